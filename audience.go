@@ -0,0 +1,55 @@
+/*
+ * jsonwt - JSON Web Tokens
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package jsonwt
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Audience is the "aud" claim, the recipients that the Token is intended for.
+// RFC 7519 allows it to be a single string or an array of strings; both are accepted when decoding.
+// It is encoded as an array unless the Token was built with Builder.SingleAudience.
+type Audience []string
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*a = nil
+		return nil
+	} else if len(data) != 0 && data[0] == '"' {
+		var aud string
+		if err := json.Unmarshal(data, &aud); err != nil {
+			return err
+		}
+		*a = Audience{aud}
+		return nil
+	}
+	var aud []string
+	if err := json.Unmarshal(data, &aud); err != nil {
+		return err
+	}
+	*a = aud
+	return nil
+}
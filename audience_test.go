@@ -0,0 +1,109 @@
+/*******************************************************************************
+jsonwt - JSON Web Tokens
+Copyright (c) 2022 Michael D Henderson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+******************************************************************************/
+
+package jsonwt_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mdhender/jsonwt"
+)
+
+func TestAudienceDecode(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		aud  string // the JSON value of the "aud" member, empty to leave it out
+		want []string
+		ok   bool
+	}{
+		{"string", `"api"`, []string{"api"}, true},
+		{"empty string", `""`, []string{""}, true},
+		{"array", `["a","b"]`, []string{"a", "b"}, true},
+		{"empty array", `[]`, nil, true},
+		{"null", `null`, nil, true},
+		{"missing", ``, nil, true},
+		{"number", `42`, nil, false},
+		{"object", `{"a":"b"}`, nil, false},
+		{"array of numbers", `["a",1]`, nil, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			payload := `{"iat":1654084800,"exp":1654085400}`
+			if tc.aud != "" {
+				payload = `{"iat":1654084800,"exp":1654085400,"aud":` + tc.aud + `}`
+			}
+			tok, err := jsonwt.Decode(rawToken(testHeader, payload))
+			if !tc.ok {
+				if err == nil {
+					t.Errorf("decode: got nil, want error")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("decode: got %v, want nil", err)
+			}
+			if got := tok.Audience(); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("audience: got %#v, want %#v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAudienceEncode(t *testing.T) {
+	f := jsonwt.NewFactory("k", newHS256(t))
+	for _, tc := range []struct {
+		name string
+		b    *jsonwt.Builder
+		aud  string // the JSON value of the "aud" member
+		want []string
+	}{
+		{"single audience", f.Builder().SingleAudience("api"), `"api"`, []string{"api"}},
+		{"single audience with claims", f.Builder().SingleAudience("api").Claims(map[string]string{"email": "bilbo@example.com"}), `"api"`, []string{"api"}},
+		{"audience", f.Builder().Audience("api"), `["api"]`, []string{"api"}},
+		{"audiences", f.Builder().Audience("a", "b"), `["a","b"]`, []string{"a", "b"}},
+		{"audience replaces single audience", f.Builder().SingleAudience("api").Audience("api"), `["api"]`, []string{"api"}},
+		{"single audience replaces audience", f.Builder().Audience("a", "b").SingleAudience("api"), `"api"`, []string{"api"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tok, err := tc.b.ExpiresIn(time.Minute).Build()
+			if err != nil {
+				t.Fatal(err)
+			} else if err = f.Sign(tok); err != nil {
+				t.Fatal(err)
+			}
+			var members map[string]json.RawMessage
+			if err = json.Unmarshal(tok.RawPayload(), &members); err != nil {
+				t.Fatal(err)
+			} else if got := string(members["aud"]); got != tc.aud {
+				t.Errorf("json: got %s, want %s", got, tc.aud)
+			}
+			decoded := decodeWithSignature(t, tok, tok.Signature())
+			if err = f.Validate(decoded); err != nil {
+				t.Fatal(err)
+			} else if got := decoded.Audience(); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("decoded: got %#v, want %#v", got, tc.want)
+			}
+		})
+	}
+}
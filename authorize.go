@@ -0,0 +1,131 @@
+/*
+ * jsonwt - JSON Web Tokens
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package jsonwt
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Rule decides if the values of a claim grant access to a resource.
+type Rule interface {
+	// Allows returns true if the values grant access.
+	Allows(values []string) bool
+}
+
+// AllOf returns a Rule that allows access only if the claim has every one of the values.
+func AllOf(values ...string) Rule {
+	return allOf(values)
+}
+
+// AnyOf returns a Rule that allows access if the claim has at least one of the values.
+func AnyOf(values ...string) Rule {
+	return anyOf(values)
+}
+
+// allOf implements the Rule interface for AllOf.
+type allOf []string
+
+// Allows implements the Rule interface.
+func (r allOf) Allows(values []string) bool {
+	for _, want := range r {
+		if !contains(values, want) {
+			return false
+		}
+	}
+	return true
+}
+
+// anyOf implements the Rule interface for AnyOf.
+type anyOf []string
+
+// Allows implements the Rule interface.
+func (r anyOf) Allows(values []string) bool {
+	for _, want := range r {
+		if contains(values, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireClaim returns a middleware that authorizes requests using a member of the Token's payload.
+// The member may be a space-delimited string (like "scope") or an array of strings (like "roles").
+// If the payload doesn't have the member, it is looked for in the private claim created by NewToken.
+// The Token must have been stored in the request's context by Middleware.
+// Requests without a Token are rejected with 401 Unauthorized.
+// Requests whose Token is not allowed by the rule are rejected with 403 Forbidden.
+func RequireClaim(member string, rule Rule) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t, ok := FromContext(r.Context())
+			if !ok || t == nil {
+				DefaultErrorHandler(w, r, ErrMissingToken)
+				return
+			} else if !rule.Allows(t.claimValues(member)) {
+				WriteChallenge(w, http.StatusForbidden, Challenge{Error: ErrorCodeInsufficientScope})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireRoles returns a middleware that authorizes requests using the "roles" member of the Token.
+// See RequireClaim for details.
+func RequireRoles(rule Rule) func(http.Handler) http.Handler {
+	return RequireClaim("roles", rule)
+}
+
+// RequireScopes returns a middleware that authorizes requests using the "scope" member of the Token.
+// See RequireClaim for details.
+func RequireScopes(rule Rule) func(http.Handler) http.Handler {
+	return RequireClaim("scope", rule)
+}
+
+// claimValues is a helper that returns the values of a member of the Token's payload.
+// If the payload doesn't have the member, the private claim created by NewToken is checked.
+// A string is split on spaces; an array must contain only strings.
+func (t *Token) claimValues(member string) []string {
+	raw, ok := t.Get(member)
+	if !ok && t.p.Claim != "" {
+		var claim map[string]json.RawMessage
+		if b, err := decode(t.p.Claim); err == nil && json.Unmarshal(b, &claim) == nil {
+			raw, ok = claim[member]
+		}
+	}
+	if !ok {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return strings.Fields(s)
+	}
+	var values []string
+	if err := json.Unmarshal(raw, &values); err == nil {
+		return values
+	}
+	return nil
+}
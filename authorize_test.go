@@ -0,0 +1,95 @@
+/*******************************************************************************
+jsonwt - JSON Web Tokens
+Copyright (c) 2022 Michael D Henderson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+******************************************************************************/
+
+package jsonwt_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mdhender/jsonwt"
+)
+
+func TestRequireClaim(t *testing.T) {
+	f := jsonwt.NewFactory("k", newHS256(t))
+	sign := func(claims interface{}) string {
+		tok, err := f.Builder().ExpiresIn(time.Minute).Claims(claims).Build()
+		if err != nil {
+			t.Fatal(err)
+		} else if err = f.Sign(tok); err != nil {
+			t.Fatal(err)
+		}
+		return tok.String()
+	}
+	scoped := sign(map[string]string{"scope": "read write"})
+	roles := sign(map[string][]string{"roles": {"user", "admin"}})
+	// the private claim created by NewToken
+	legacy, err := f.Token(time.Minute, map[string][]string{"roles": {"user"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const insufficientScope = `Bearer error="insufficient_scope"`
+	for _, tc := range []struct {
+		name      string
+		mw        func(http.Handler) http.Handler
+		token     string
+		code      int
+		challenge string
+	}{
+		{"any scope", jsonwt.RequireScopes(jsonwt.AnyOf("admin", "write")), scoped, http.StatusOK, ""},
+		{"all scopes", jsonwt.RequireScopes(jsonwt.AllOf("read", "write")), scoped, http.StatusOK, ""},
+		{"missing scope", jsonwt.RequireScopes(jsonwt.AllOf("read", "delete")), scoped, http.StatusForbidden, insufficientScope},
+		{"no scope member", jsonwt.RequireScopes(jsonwt.AnyOf("read")), roles, http.StatusForbidden, insufficientScope},
+		{"any role", jsonwt.RequireRoles(jsonwt.AnyOf("admin")), roles, http.StatusOK, ""},
+		{"all roles", jsonwt.RequireRoles(jsonwt.AllOf("admin", "user")), roles, http.StatusOK, ""},
+		{"missing role", jsonwt.RequireRoles(jsonwt.AllOf("admin", "owner")), roles, http.StatusForbidden, insufficientScope},
+		{"legacy claim", jsonwt.RequireRoles(jsonwt.AnyOf("user")), legacy.String(), http.StatusOK, ""},
+		{"legacy claim missing role", jsonwt.RequireRoles(jsonwt.AnyOf("admin")), legacy.String(), http.StatusForbidden, insufficientScope},
+		{"custom member", jsonwt.RequireClaim("scope", jsonwt.AnyOf("read")), scoped, http.StatusOK, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mw := func(next http.Handler) http.Handler {
+				return jsonwt.Middleware(f, nil)(tc.mw(next))
+			}
+			w, _, called := serve(mw, bearerRequest(tc.token))
+			if w.Code != tc.code {
+				t.Errorf("code: got %d, want %d", w.Code, tc.code)
+			} else if got := w.Header().Get("WWW-Authenticate"); got != tc.challenge {
+				t.Errorf("challenge: got %q, want %q", got, tc.challenge)
+			} else if called != (tc.code == http.StatusOK) {
+				t.Errorf("next: got %v, want %v", called, tc.code == http.StatusOK)
+			}
+		})
+	}
+
+	// without the authentication middleware, there is no Token in the context
+	w, _, called := serve(jsonwt.RequireScopes(jsonwt.AnyOf("read")), httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusUnauthorized || called {
+		t.Errorf("no token: got %d, want %d", w.Code, http.StatusUnauthorized)
+	} else if got := w.Header().Get("WWW-Authenticate"); got != "Bearer" {
+		t.Errorf("no token: challenge: got %q, want %q", got, "Bearer")
+	}
+}
@@ -0,0 +1,176 @@
+/*
+ * jsonwt - JSON Web Tokens
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package jsonwt
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// NewBuilder returns a Builder for an unsigned Token.
+// The Token's timestamps are set using the system clock.
+func NewBuilder() *Builder {
+	return newBuilder(systemClock{})
+}
+
+// Builder returns a Builder for an unsigned Token.
+// The Token's timestamps are set using the factory's clock.
+// Use Factory.Sign to sign the Token after it is built.
+func (f *Factory) Builder() *Builder {
+	return newBuilder(f.getClock())
+}
+
+// newBuilder is a helper to create a Builder that uses the clock for the timestamps.
+func newBuilder(clock Clock) *Builder {
+	b := &Builder{clock: clock}
+	b.t.h.Version = 1
+	b.t.h.TokenType = "JWT"
+	b.t.p.IssuedAt = NewNumericDate(clock.Now())
+	b.t.clock = clock
+	return b
+}
+
+// Builder sets the registered claims of a Token before it is signed.
+// The methods return the Builder so that calls can be chained.
+// By default, the Token is issued at the current time and has no expiration time.
+type Builder struct {
+	clock Clock
+	t     Token
+	err   error // first error found while building
+}
+
+// Audience sets the recipients that the Token is intended for ("aud").
+func (b *Builder) Audience(aud ...string) *Builder {
+	b.t.p.Audience = append(Audience(nil), aud...)
+	b.t.p.singleAudience = false
+	return b
+}
+
+// Build returns the unsigned Token.
+// It returns an error if the private claims could not be marshalled
+// or if the Token has no expiration time.
+func (b *Builder) Build() (*Token, error) {
+	if b.err != nil {
+		return nil, b.err
+	} else if b.t.p.ExpirationTime == 0 {
+		return nil, ErrMissingExpiration
+	}
+	t := b.t
+	t.p.Audience = append(Audience(nil), b.t.p.Audience...)
+	return &t, nil
+}
+
+// Claim sets the private payload for use by the application.
+// The claim will be marshalled to JSON, then base64 encoded.
+func (b *Builder) Claim(claim interface{}) *Builder {
+	if claim == nil {
+		b.t.p.Claim = ""
+		return b
+	}
+	data, err := json.Marshal(claim)
+	if err != nil {
+		if b.err == nil {
+			b.err = err
+		}
+		return b
+	}
+	b.t.p.Claim = encode(data)
+	return b
+}
+
+// Claims sets the private claims for use by the application.
+// Unlike Claim, the claims are added as top-level members of the payload so that other
+// JWT libraries can read them. The claims must marshal to a JSON object, and the object
+// must not have a member with the name of a registered claim ("iss", "sub", etc.), ignoring case.
+func (b *Builder) Claims(claims interface{}) *Builder {
+	if claims == nil {
+		b.t.p.private = nil
+		return b
+	}
+	members, err := privateClaims(claims)
+	if err != nil {
+		if b.err == nil {
+			b.err = err
+		}
+		return b
+	}
+	b.t.p.private = members
+	return b
+}
+
+// ContentType sets the content type in the Token's header ("cty").
+func (b *Builder) ContentType(cty string) *Builder {
+	b.t.h.ContentType = cty
+	return b
+}
+
+// ExpiresAt sets the time on and after which the Token must not be accepted ("exp").
+func (b *Builder) ExpiresAt(exp time.Time) *Builder {
+	b.t.p.ExpirationTime = NewNumericDate(exp)
+	return b
+}
+
+// ExpiresIn sets the expiration time ("exp") to the current time plus the time-to-live.
+func (b *Builder) ExpiresIn(ttl time.Duration) *Builder {
+	b.t.p.ExpirationTime = NewNumericDate(b.clock.Now().Add(ttl))
+	return b
+}
+
+// ID sets the unique identifier of the Token ("jti").
+func (b *Builder) ID(jti string) *Builder {
+	b.t.p.JWTID = jti
+	return b
+}
+
+// IssuedAt sets the time at which the Token was issued ("iat").
+func (b *Builder) IssuedAt(iat time.Time) *Builder {
+	b.t.p.IssuedAt = NewNumericDate(iat)
+	return b
+}
+
+// Issuer sets the principal that issued the Token ("iss").
+func (b *Builder) Issuer(iss string) *Builder {
+	b.t.p.Issuer = iss
+	return b
+}
+
+// NotBefore sets the time on which the Token will start to be accepted ("nbf").
+func (b *Builder) NotBefore(nbf time.Time) *Builder {
+	b.t.p.NotBefore = NewNumericDate(nbf)
+	return b
+}
+
+// SingleAudience sets a single recipient that the Token is intended for ("aud").
+// Unlike Audience, it is encoded as a JSON string instead of an array of strings.
+func (b *Builder) SingleAudience(aud string) *Builder {
+	b.t.p.Audience = Audience{aud}
+	b.t.p.singleAudience = true
+	return b
+}
+
+// Subject sets the subject of the Token ("sub").
+func (b *Builder) Subject(sub string) *Builder {
+	b.t.p.Subject = sub
+	return b
+}
@@ -0,0 +1,84 @@
+/*
+ * jsonwt - JSON Web Tokens
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package jsonwt
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Error codes for the Bearer challenge (RFC 6750 section 3.1).
+const (
+	ErrorCodeInvalidRequest    = "invalid_request"
+	ErrorCodeInvalidToken      = "invalid_token"
+	ErrorCodeInsufficientScope = "insufficient_scope"
+)
+
+// Challenge is the Bearer challenge sent in the WWW-Authenticate header (RFC 6750 section 3).
+// Empty fields are not included in the header.
+type Challenge struct {
+	Realm            string
+	Scope            string
+	Error            string // one of the ErrorCode constants
+	ErrorDescription string // human-readable explanation of the error
+}
+
+// String implements the Stringer interface.
+// It returns the value for the WWW-Authenticate header.
+func (c Challenge) String() string {
+	var params []string
+	if c.Realm != "" {
+		params = append(params, `realm="`+quote(c.Realm)+`"`)
+	}
+	if c.Scope != "" {
+		params = append(params, `scope="`+quote(c.Scope)+`"`)
+	}
+	if c.Error != "" {
+		params = append(params, `error="`+quote(c.Error)+`"`)
+	}
+	if c.ErrorDescription != "" {
+		params = append(params, `error_description="`+quote(c.ErrorDescription)+`"`)
+	}
+	if len(params) == 0 {
+		return "Bearer"
+	}
+	return "Bearer " + strings.Join(params, ", ")
+}
+
+// WriteChallenge sets the WWW-Authenticate header and replies to the request with the status code.
+func WriteChallenge(w http.ResponseWriter, code int, c Challenge) {
+	w.Header().Set("WWW-Authenticate", c.String())
+	http.Error(w, http.StatusText(code), code)
+}
+
+// quote is a helper function that removes the characters that RFC 6750 does not allow in
+// attribute values (the double quote, the backslash, and anything that isn't printable ASCII).
+func quote(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			return -1
+		}
+		return r
+	}, s)
+}
@@ -26,24 +26,164 @@ package jsonwt
 import "encoding/json"
 
 // Claim retrieves the private payload from the Token and marshals it into the given variable.
+// The private payload is either the base64 encoded "claim" member created by NewToken
+// or the top-level private claims created by Builder.Claims.
 // It returns errors if the Token is not valid, has no private payload, or there's an error unmarshalling the data.
+// If the Token is not valid, the error is the one returned by Token.Validate.
 func (t *Token) Claim(v interface{}) error {
 	if t == nil {
 		return ErrBadToken
-	} else if !t.IsValid() {
-		return ErrInvalid
-	} else if t.p.Claim == "" {
+	} else if err := t.Validate(); err != nil {
+		return err
+	} else if t.p.Claim != "" {
+		b, err := decode(t.p.Claim)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(b, v)
+	} else if len(t.p.private) == 0 {
 		return ErrMissingClaim
 	}
-	b, err := decode(t.p.Claim)
+	b, err := json.Marshal(t.p.private)
 	if err != nil {
 		return err
 	}
 	return json.Unmarshal(b, v)
 }
 
+// Claims returns every member of the Token's payload, including the registered
+// claims and any members added by other issuers (for example, "scope" or "email").
+// The values are unmarshalled as by encoding/json into an interface value.
+// Use Get to unmarshal a single member into a specific type.
+func (t *Token) Claims() map[string]any {
+	members, err := t.payloadMembers()
+	if err != nil {
+		return nil
+	}
+	claims := make(map[string]any, len(members))
+	for name, raw := range members {
+		var value any
+		if err := json.Unmarshal(raw, &value); err != nil {
+			continue
+		}
+		claims[name] = value
+	}
+	return claims
+}
+
+// Get returns the JSON representation of the named member of the Token's payload.
+// It returns false if the payload does not have the member.
+func (t *Token) Get(name string) (json.RawMessage, bool) {
+	members, err := t.payloadMembers()
+	if err != nil {
+		return nil, false
+	}
+	raw, ok := members[name]
+	if !ok {
+		return nil, false
+	}
+	return append(json.RawMessage(nil), raw...), true
+}
+
 // HasClaim returns true if Token has a claim defined in its payload.
 // Note: Token.Claim may fail to return a claim if the Token is invalid.
 func (t *Token) HasClaim() bool {
-	return t != nil && t.p.Claim != ""
+	return t != nil && (t.p.Claim != "" || len(t.p.private) != 0)
+}
+
+// registeredClaims are the payload members that can't be used as private claims.
+// It includes "claim" since that member holds the private payload created by NewToken.
+var registeredClaims = map[string]bool{
+	"iss":   true,
+	"sub":   true,
+	"aud":   true,
+	"exp":   true,
+	"nbf":   true,
+	"iat":   true,
+	"jti":   true,
+	"claim": true,
+}
+
+// marshalPayload is a helper that returns the JSON representation of the Token's payload.
+// Private claims are merged into the payload as top-level members.
+// A single audience is encoded as a string if the Token was built with Builder.SingleAudience.
+func (t *Token) marshalPayload() ([]byte, error) {
+	b, err := json.Marshal(t.p)
+	if err != nil || (len(t.p.private) == 0 && !t.p.singleAudience) {
+		return b, err
+	}
+	members := make(map[string]json.RawMessage)
+	if err = json.Unmarshal(b, &members); err != nil {
+		return nil, err
+	}
+	for name, value := range t.p.private {
+		members[name] = value
+	}
+	if t.p.singleAudience && len(t.p.Audience) == 1 {
+		if members["aud"], err = json.Marshal(t.p.Audience[0]); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(members)
+}
+
+// payloadMembers is a helper that returns every member of the Token's payload.
+// The map must not be modified by the caller.
+func (t *Token) payloadMembers() (map[string]json.RawMessage, error) {
+	if t == nil {
+		return nil, ErrBadToken
+	} else if t.p.members != nil {
+		return t.p.members, nil
+	}
+	b, err := t.marshalPayload() // the Token has not been decoded or signed
+	if err != nil {
+		return nil, err
+	}
+	members := make(map[string]json.RawMessage)
+	if err = json.Unmarshal(b, &members); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// unmarshalPayload is a helper that sets the Token's payload from its JSON representation.
+// Members that are not registered claims are kept as private claims.
+func (t *Token) unmarshalPayload(data []byte) error {
+	if err := json.Unmarshal(data, &t.p); err != nil {
+		return err
+	}
+	members := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+	t.p.members, t.p.private = members, nil
+	for name, value := range members {
+		if registeredClaims[name] {
+			continue
+		} else if t.p.private == nil {
+			t.p.private = make(map[string]json.RawMessage)
+		}
+		t.p.private[name] = value
+	}
+	return nil
+}
+
+// privateClaims is a helper function that returns the members of the JSON object representation of claims.
+// It returns ErrBadClaims if claims is not a JSON object or ErrRegisteredClaim if a member is a registered claim.
+// Names are compared without regard to case so that the Parser won't reject the payload for duplicate members.
+func privateClaims(claims interface{}) (map[string]json.RawMessage, error) {
+	b, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+	var members map[string]json.RawMessage
+	if err = json.Unmarshal(b, &members); err != nil || members == nil {
+		return nil, ErrBadClaims
+	}
+	for name := range members {
+		if registeredClaims[foldName(name)] {
+			return nil, ErrRegisteredClaim
+		}
+	}
+	return members, nil
 }
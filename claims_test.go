@@ -0,0 +1,207 @@
+/*******************************************************************************
+jsonwt - JSON Web Tokens
+Copyright (c) 2022 Michael D Henderson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+******************************************************************************/
+
+package jsonwt_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mdhender/jsonwt"
+)
+
+func TestTypedTokenClaims(t *testing.T) {
+	type claims struct {
+		Roles []string `json:"roles"`
+		Email string   `json:"email"`
+	}
+	tf := jsonwt.NewTypedFactory[claims](jsonwt.NewFactory("k", newHS256(t)))
+	tok, err := tf.Token(time.Minute, claims{Roles: []string{"admin"}, Email: "bilbo@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tt, err := tf.Parse(tok.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the typed claims and the raw members are both available on a TypedToken
+	if got := tt.Claims().Email; got != "bilbo@example.com" {
+		t.Errorf("claims: email: got %q, want %q", got, "bilbo@example.com")
+	}
+	members := tt.Token.Claims()
+	if got, ok := members["email"].(string); !ok || got != "bilbo@example.com" {
+		t.Errorf("members: email: got %v, want %q", members["email"], "bilbo@example.com")
+	} else if _, ok = members["iat"].(float64); !ok {
+		t.Errorf("members: iat: got %T, want float64", members["iat"])
+	}
+	if raw, ok := tt.Get("roles"); !ok || string(raw) != `["admin"]` {
+		t.Errorf("get: roles: got %s, want %s", raw, `["admin"]`)
+	} else if _, ok = tt.Get("scope"); ok {
+		t.Errorf("get: scope: got true, want false")
+	}
+}
+
+func TestTypedTokenZeroClaims(t *testing.T) {
+	type claims struct {
+		Roles []string `json:"roles,omitempty"`
+		Email string   `json:"email,omitempty"`
+	}
+	tf := jsonwt.NewTypedFactory[claims](jsonwt.NewFactory("k", newHS256(t)))
+	tok, err := tf.Token(time.Minute, claims{})
+	if err != nil {
+		t.Fatal(err)
+	} else if tok.HasClaim() {
+		t.Errorf("token: HasClaim: got true, want false")
+	}
+	tt, err := tf.Parse(tok.String())
+	if err != nil {
+		t.Fatalf("parse: got %v, want nil", err)
+	} else if got := tt.Claims(); !reflect.DeepEqual(got, claims{}) {
+		t.Errorf("claims: got %+v, want the zero value", got)
+	}
+
+	// tokens from other issuers may not have any private claims
+	f := jsonwt.NewFactory("k", newHS256(t))
+	f.SetClock(jsonwt.NewFakeClock(t0))
+	tt, err = jsonwt.NewTypedFactory[claims](f).Validate(signedToken(t, f, testPayload))
+	if err != nil {
+		t.Fatalf("validate: got %v, want nil", err)
+	} else if got := tt.Claims(); !reflect.DeepEqual(got, claims{}) {
+		t.Errorf("validate: claims: got %+v, want the zero value", got)
+	}
+}
+
+func TestBuilderClaimsRejectsBadClaims(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		claims interface{}
+		want   error
+	}{
+		{"registered claim", map[string]string{"email": "bilbo@example.com", "sub": "bilbo"}, jsonwt.ErrRegisteredClaim},
+		{"registered struct field", struct {
+			Expires int64 `json:"exp"`
+		}{1654085400}, jsonwt.ErrRegisteredClaim},
+		{"registered claim case", map[string]int64{"EXP": 1654085400}, jsonwt.ErrRegisteredClaim},
+		{"legacy claim member", map[string]string{"claim": "e30"}, jsonwt.ErrRegisteredClaim},
+		{"string", "bilbo", jsonwt.ErrBadClaims},
+		{"number", 42, jsonwt.ErrBadClaims},
+		{"array", []string{"admin"}, jsonwt.ErrBadClaims},
+		{"null", (*struct{})(nil), jsonwt.ErrBadClaims},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := jsonwt.NewBuilder().ExpiresIn(time.Minute).Claims(tc.claims).Build()
+			if !errors.Is(err, tc.want) {
+				t.Errorf("got %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestTokenClaim(t *testing.T) {
+	type claims struct {
+		Email string   `json:"email"`
+		Roles []string `json:"roles,omitempty"`
+	}
+	legacy := base64.RawURLEncoding.EncodeToString([]byte(`{"email":"frodo@example.com"}`))
+	for _, tc := range []struct {
+		name    string
+		payload string
+		want    claims
+		err     error
+	}{
+		{"legacy claim member", `{"iat":1654084800,"exp":1654085400,"claim":"` + legacy + `"}`, claims{Email: "frodo@example.com"}, nil},
+		{"flat members", `{"iat":1654084800,"exp":1654085400,"email":"bilbo@example.com","roles":["admin"]}`, claims{Email: "bilbo@example.com", Roles: []string{"admin"}}, nil},
+		{"legacy claim member wins", `{"iat":1654084800,"exp":1654085400,"claim":"` + legacy + `","email":"bilbo@example.com"}`, claims{Email: "frodo@example.com"}, nil},
+		{"no private claims", testPayload, claims{}, jsonwt.ErrMissingClaim},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := jsonwt.NewFactory("k", newHS256(t))
+			f.SetClock(jsonwt.NewFakeClock(t0))
+			tok := signedToken(t, f, tc.payload)
+			var got claims
+			if err := tok.Claim(&got); !errors.Is(err, tc.err) {
+				t.Fatalf("claim: got %v, want %v", err, tc.err)
+			} else if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("claim: got %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTokenClaimRoundTrip(t *testing.T) {
+	type claims struct {
+		Email string `json:"email"`
+	}
+	want := claims{Email: "bilbo@example.com"}
+	f := jsonwt.NewFactory("k", newHS256(t))
+	for name, b := range map[string]*jsonwt.Builder{
+		"Claim":  f.Builder().Claim(want),
+		"Claims": f.Builder().Claims(want),
+	} {
+		t.Run(name, func(t *testing.T) {
+			tok, err := b.ExpiresIn(time.Minute).Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got claims
+			if err = tok.Claim(&got); !errors.Is(err, jsonwt.ErrUnsigned) {
+				t.Errorf("unsigned: got %v, want %v", err, jsonwt.ErrUnsigned)
+			}
+			if err = f.Sign(tok); err != nil {
+				t.Fatal(err)
+			}
+			decoded := decodeWithSignature(t, tok, tok.Signature())
+			if err = f.Validate(decoded); err != nil {
+				t.Fatal(err)
+			} else if err = decoded.Claim(&got); err != nil {
+				t.Fatalf("claim: got %v, want nil", err)
+			} else if got != want {
+				t.Errorf("claim: got %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+// signedToken is a helper that returns a token with the test header and the given payload,
+// signed with the test secret and validated by the factory.
+func signedToken(t *testing.T, f *jsonwt.Factory, payload string) *jsonwt.Token {
+	t.Helper()
+	data := rawToken(testHeader, payload)
+	data = data[:strings.LastIndexByte(data, '.')]
+	sig, err := newHS256(t).Sign([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tok, err := jsonwt.Decode(data + "." + base64.RawURLEncoding.EncodeToString(sig))
+	if err != nil {
+		t.Fatal(err)
+	} else if err = f.Validate(tok); err != nil {
+		t.Fatal(err)
+	}
+	return tok
+}
@@ -51,7 +51,8 @@ type CLAIM struct {
 }
 
 func run() error {
-	s, err := signers.NewHS256([]byte("secret"))
+	// HS256 requires a secret of at least 32 bytes.
+	s, err := signers.NewHS256([]byte("this-is-not-a-very-good-secret!!"))
 	if err != nil {
 		return err
 	}
@@ -62,6 +63,28 @@ func run() error {
 		Roles: []string{"one", "two"},
 	}
 
+	// the middleware rejects requests without a valid token before they reach the handler
+	validate := jsonwt.Middleware(f, &jsonwt.MiddlewareOptions{
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			log.Printf("%s %s: %+v\n", r.Method, r.URL, err)
+			jsonwt.DefaultErrorHandler(w, r, err)
+		},
+	})
+	// CLAIM marshals its roles as the "Roles" member of the private claim
+	requireRole := jsonwt.RequireClaim("Roles", jsonwt.AnyOf("one"))
+	protected := validate(requireRole(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t, _ := jsonwt.FromContext(r.Context())
+		var c CLAIM
+		err := t.Claim(&c)
+		if err != nil {
+			log.Printf("%s %s: %+v\n", r.Method, r.URL, err)
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		log.Printf("%s %s: %+v\n", r.Method, r.URL, c)
+		w.WriteHeader(http.StatusNoContent)
+	})))
+
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/" {
 			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
@@ -90,27 +113,7 @@ func run() error {
 			w.WriteHeader(http.StatusOK)
 			_, _ = w.Write(b)
 		case http.MethodPost:
-			t := jsonwt.FromRequest(r)
-			if t == nil {
-				log.Printf("%s %s: no bearer token\n", r.Method, r.URL)
-				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
-				return
-			}
-			err := f.Validate(t)
-			if err != nil {
-				log.Printf("%s %s: %+v\n", r.Method, r.URL, err)
-				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
-				return
-			}
-			var c CLAIM
-			err = t.Claim(&c)
-			if err != nil {
-				log.Printf("%s %s: %+v\n", r.Method, r.URL, err)
-				http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
-				return
-			}
-			log.Printf("%s %s: %+v\n", r.Method, r.URL, c)
-			w.WriteHeader(http.StatusNoContent)
+			protected.ServeHTTP(w, r)
 		default:
 			log.Printf("%s %s: not found\n", r.Method, r.URL)
 			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
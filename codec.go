@@ -23,39 +23,12 @@ SOFTWARE.
 
 package jsonwt
 
-import (
-	"encoding/base64"
-	"encoding/json"
-	"strings"
-)
+import "encoding/base64"
 
-// Decode expects the data to look like header.payload.signature if it is a valid Token
+// Decode expects the data to look like header.payload.signature if it is a valid Token.
+// It uses the DefaultParser to decode the data.
 func Decode(data string) (*Token, error) {
-	sections := strings.Split(data, ".")
-	if len(sections) != 3 || len(sections[0]) == 0 || len(sections[1]) == 0 || len(sections[2]) == 0 {
-		return nil, ErrBadToken
-	}
-
-	var t Token
-	t.h.b64 = sections[0]
-	t.p.b64 = sections[1]
-	t.s = sections[2]
-
-	// the header is base64 encoded JSON
-	if rawHeader, err := decode(t.h.b64); err != nil {
-		return nil, err
-	} else if err = json.Unmarshal(rawHeader, &t.h); err != nil {
-		return nil, err
-	}
-
-	// the payload is base64 encoded JSON
-	if rawPayload, err := decode(t.p.b64); err != nil {
-		return nil, err
-	} else if err = json.Unmarshal(rawPayload, &t.p); err != nil {
-		return nil, err
-	}
-
-	return &t, nil
+	return DefaultParser.Decode(data)
 }
 
 // decode is a helper function for converting a string containing the base64 representation to raw bytes
@@ -63,6 +36,19 @@ func decode(raw string) (b []byte, err error) {
 	return base64.RawURLEncoding.DecodeString(raw)
 }
 
+// decodeSignature is a helper function for converting the base64 representation of a signature to raw bytes.
+// It uses strict decoding so that a signature has exactly one valid representation.
+func decodeSignature(raw string) ([]byte, error) {
+	if raw == "" {
+		return nil, ErrInvalidSignature
+	}
+	b, err := base64.RawURLEncoding.Strict().DecodeString(raw)
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	return b, nil
+}
+
 // encode is a helper function for converting a slice of raw bytes to a string containg the base64 representation
 func encode(b []byte) string {
 	return base64.RawURLEncoding.EncodeToString(b)
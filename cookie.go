@@ -24,36 +24,113 @@ SOFTWARE.
 package jsonwt
 
 import (
+	"fmt"
 	"net/http"
 	"time"
 )
 
+// DefaultCookieConfig is used by SetCookie, DeleteCookie, FromCookie, and ExtractToken.
+// Change it before serving requests to configure the cookie for the whole application.
+var DefaultCookieConfig = &CookieConfig{
+	Name:   "jsonwt",
+	Path:   "/",
+	MinAge: 15 * time.Second,
+	MaxAge: 14 * 24 * time.Hour,
+}
+
+// CookieConfig describes the cookie that holds the Token.
+// The cookie is always HttpOnly.
+type CookieConfig struct {
+	Name     string
+	Domain   string
+	Path     string
+	Secure   bool
+	SameSite http.SameSite
+	// HostPrefix adds the "__Host-" prefix to the name of the cookie.
+	// Browsers only accept these cookies if they are Secure, have a Path of "/", and have no Domain,
+	// so those values are used instead of the ones in the config.
+	HostPrefix bool
+	// MinAge and MaxAge bound the cookie's Max-Age, which is otherwise the time until the Token expires.
+	// A zero value means there is no bound.
+	MinAge time.Duration
+	MaxAge time.Duration
+}
+
+// Delete removes the cookie that may contain the Token.
+func (c *CookieConfig) Delete(w http.ResponseWriter) {
+	cookie := c.cookie()
+	cookie.MaxAge = -1
+	http.SetCookie(w, cookie)
+}
+
+// Extract returns the Token from the cookie in the request.
+// It returns ErrMissingToken if there is no cookie
+// or an error wrapping ErrMalformedToken if the cookie can't be decoded.
+func (c *CookieConfig) Extract(r *http.Request) (*Token, error) {
+	cookie, err := r.Cookie(c.name())
+	if err != nil {
+		return nil, ErrMissingToken
+	}
+	t, err := Decode(cookie.Value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedToken, err)
+	}
+	return t, nil
+}
+
+// Set creates a cookie containing the Token and sends it to the client.
+// The cookie expires when the Token does, within the MinAge and MaxAge bounds.
+func (c *CookieConfig) Set(w http.ResponseWriter, t *Token) {
+	var maxAge time.Duration
+	if t.p.ExpirationTime != 0 {
+		maxAge = t.p.ExpirationTime.Time().Sub(t.now())
+	}
+	if c.MinAge != 0 && maxAge < c.MinAge {
+		maxAge = c.MinAge
+	} else if c.MaxAge != 0 && maxAge > c.MaxAge {
+		maxAge = c.MaxAge
+	}
+	cookie := c.cookie()
+	cookie.Value = t.String()
+	cookie.MaxAge = int(maxAge.Seconds())
+	if cookie.MaxAge <= 0 {
+		cookie.MaxAge = -1 // the Token has already expired
+	}
+	http.SetCookie(w, cookie)
+}
+
+// cookie is a helper that returns a cookie with the attributes from the config.
+func (c *CookieConfig) cookie() *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     c.name(),
+		Domain:   c.Domain,
+		Path:     c.Path,
+		Secure:   c.Secure,
+		SameSite: c.SameSite,
+		HttpOnly: true,
+	}
+	if c.HostPrefix {
+		cookie.Domain, cookie.Path, cookie.Secure = "", "/", true
+	}
+	return cookie
+}
+
+// name is a helper that returns the name of the cookie, including any prefix.
+func (c *CookieConfig) name() string {
+	if c.HostPrefix {
+		return "__Host-" + c.Name
+	}
+	return c.Name
+}
+
 // DeleteCookie is a helper function to delete a Cookie that may contain the Token.
+// It uses the DefaultCookieConfig.
 func DeleteCookie(w http.ResponseWriter) {
-	http.SetCookie(w, &http.Cookie{
-		Name:     "jsonwt",
-		Path:     "/",
-		MaxAge:   -1,
-		HttpOnly: true,
-	})
+	DefaultCookieConfig.Delete(w)
 }
 
 // SetCookie is a helper function to create a Cookie containing the Token.
+// It uses the DefaultCookieConfig.
 func SetCookie(w http.ResponseWriter, t *Token) {
-	var maxAge int
-	if t.p.ExpirationTime != 0 {
-		maxAge = int(time.Unix(t.p.ExpirationTime, 0).Sub(time.Now().UTC()).Seconds())
-	}
-	if maxAge < 15 {
-		maxAge = 15
-	} else if maxAge > 14*24*60*60 {
-		maxAge = 14 * 24 * 60 * 60
-	}
-	http.SetCookie(w, &http.Cookie{
-		Name:     "jsonwt",
-		Path:     "/",
-		Value:    t.String(),
-		MaxAge:   maxAge,
-		HttpOnly: true,
-	})
+	DefaultCookieConfig.Set(w, t)
 }
@@ -0,0 +1,164 @@
+/*******************************************************************************
+jsonwt - JSON Web Tokens
+Copyright (c) 2022 Michael D Henderson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+******************************************************************************/
+
+package jsonwt_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mdhender/jsonwt"
+)
+
+func TestCookieMaxAge(t *testing.T) {
+	bounded := &jsonwt.CookieConfig{Name: "jsonwt", MinAge: 15 * time.Second, MaxAge: 14 * 24 * time.Hour}
+	unbounded := &jsonwt.CookieConfig{Name: "jsonwt"}
+	for _, tc := range []struct {
+		name string
+		cc   *jsonwt.CookieConfig
+		ttl  time.Duration
+		want int
+	}{
+		{"below min", bounded, 70 * time.Second, 15},
+		{"within bounds", bounded, time.Hour, 3600 - 60},
+		{"above max", bounded, 30 * 24 * time.Hour, 14 * 24 * 60 * 60},
+		{"expired", bounded, time.Second, 15},
+		{"unbounded", unbounded, 30 * 24 * time.Hour, 30*24*60*60 - 60},
+		{"unbounded expired", unbounded, time.Second, -1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			clock := jsonwt.NewFakeClock(t0)
+			f := jsonwt.NewFactory("k", newHS256(t))
+			f.SetClock(clock)
+			tok, err := f.Token(tc.ttl, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the cookie expires with the token, so the time since it was issued is not included
+			clock.Advance(time.Minute)
+			w := httptest.NewRecorder()
+			tc.cc.Set(w, tok)
+			cookies := w.Result().Cookies()
+			if len(cookies) != 1 {
+				t.Fatalf("cookies: got %d, want 1", len(cookies))
+			}
+			if cookies[0].MaxAge != tc.want {
+				t.Errorf("max-age: got %d, want %d", cookies[0].MaxAge, tc.want)
+			}
+		})
+	}
+}
+
+func TestCookieRoundTrip(t *testing.T) {
+	f := jsonwt.NewFactory("k", newHS256(t))
+	tok, err := f.Token(time.Hour, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	tok.SetCookie(w)
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, c := range w.Result().Cookies() {
+		r.AddCookie(c)
+	}
+	if got := jsonwt.FromCookie(r); got == nil || got.String() != tok.String() {
+		t.Errorf("from cookie: got %v, want the token", got)
+	}
+	if got := jsonwt.FromRequest(r); got == nil || got.String() != tok.String() {
+		t.Errorf("from request: got %v, want the token", got)
+	}
+	w2, got, called := serve(jsonwt.Middleware(f, nil), r)
+	if !called || got == nil || got.String() != tok.String() {
+		t.Errorf("middleware: got %d, want the token in the context", w2.Code)
+	}
+
+	// a request that uses a different scheme may still authenticate with the cookie
+	r.SetBasicAuth("bilbo", "baggins")
+	if got, err := jsonwt.ExtractToken(r, nil); err != nil || got.String() != tok.String() {
+		t.Errorf("other scheme: got %v, want the token", err)
+	}
+
+	w = httptest.NewRecorder()
+	tok.DeleteCookie(w)
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != "jsonwt" || cookies[0].MaxAge >= 0 {
+		t.Errorf("delete: got %v, want an expired jsonwt cookie", cookies)
+	}
+}
+
+func TestCookieConfig(t *testing.T) {
+	tok, err := jsonwt.NewFactory("k", newHS256(t)).Token(time.Hour, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc := &jsonwt.CookieConfig{
+		Name:       "session",
+		Domain:     "example.com", // ignored because of the prefix
+		Path:       "/api",        // ignored because of the prefix
+		SameSite:   http.SameSiteStrictMode,
+		HostPrefix: true,
+		MaxAge:     time.Hour,
+	}
+
+	w := httptest.NewRecorder()
+	cc.Set(w, tok)
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("set: got %d cookies, want 1", len(cookies))
+	}
+	c := cookies[0]
+	if c.Name != "__Host-session" || c.Value != tok.String() || c.Path != "/" || c.Domain != "" ||
+		!c.Secure || !c.HttpOnly || c.SameSite != http.SameSiteStrictMode {
+		t.Errorf("set: got %+v", c)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(c)
+	if got, err := jsonwt.ExtractToken(r, &jsonwt.ExtractOptions{Cookie: cc}); err != nil || got.String() != tok.String() {
+		t.Errorf("extract: got %v, want the token", err)
+	}
+	// the default config looks for a different cookie
+	if _, err := jsonwt.ExtractToken(r, nil); !errors.Is(err, jsonwt.ErrMissingToken) {
+		t.Errorf("default: got %v, want %v", err, jsonwt.ErrMissingToken)
+	} else if got := jsonwt.FromCookie(r); got != nil {
+		t.Errorf("from cookie: got %v, want nil", got)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(&http.Cookie{Name: "__Host-session", Value: "not-a-token"})
+	if _, err := cc.Extract(r); !errors.Is(err, jsonwt.ErrMalformedToken) {
+		t.Errorf("malformed: got %v, want %v", err, jsonwt.ErrMalformedToken)
+	}
+
+	w = httptest.NewRecorder()
+	cc.Delete(w)
+	cookies = w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != "__Host-session" || cookies[0].MaxAge >= 0 || !cookies[0].Secure || cookies[0].Path != "/" {
+		t.Errorf("delete: got %+v, want an expired __Host-session cookie", cookies)
+	}
+}
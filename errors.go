@@ -23,18 +23,61 @@ SOFTWARE.
 
 package jsonwt
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 var ErrBadFactory = errors.New("bad factory")
+var ErrBadClaims = errors.New("bad claims")
+var ErrBadKey = errors.New("bad key")
+var ErrBadRequest = errors.New("bad request")
 var ErrBadToken = errors.New("bad token")
+var ErrBadTokenType = errors.New("bad token type")
+var ErrBadVersion = errors.New("bad version")
+var ErrDuplicateKID = errors.New("duplicate kid")
+var ErrDuplicateMember = errors.New("duplicate member")
+var ErrExpired = errors.New("expired")
 var ErrInvalid = errors.New("invalid token")
+var ErrInvalidAudience = errors.New("invalid audience")
+var ErrInvalidIssuer = errors.New("invalid issuer")
+var ErrInvalidSignature = errors.New("invalid signature")
+var ErrInvalidSubject = errors.New("invalid subject")
+var ErrMalformedToken = errors.New("malformed token")
+var ErrMissingAuthHeader = errors.New("missing auth header")
 var ErrMissingClaim = errors.New("missing claim")
+var ErrMissingExpiration = errors.New("missing expiration")
+var ErrMissingIssuedAt = errors.New("missing issued at")
+var ErrMissingToken = errors.New("missing token")
+var ErrNotBearer = errors.New("not a bearer token")
+var ErrNotMyAlgorithm = errors.New("not my algorithm")
+var ErrNotMyKID = errors.New("not my kid")
+var ErrNotYetValid = errors.New("not yet valid")
+var ErrRegisteredClaim = errors.New("registered claim")
+var ErrTokenTooLong = errors.New("token too long")
+var ErrTooOld = errors.New("too old")
 var ErrUnauthorized = errors.New("unauthorized")
+var ErrUnsigned = errors.New("unsigned")
+var ErrUnsupportedCritical = errors.New("unsupported critical header")
 
-//var ErrBadRequest = errors.New("bad request")
-//var ErrExpired = errors.New("expired")
-//var ErrInvalidSignature = errors.New("invalid signature")
-//var ErrMissingAuthHeader = errors.New("missing auth header")
 //var ErrMissingSigner = errors.New("missing signer")
-//var ErrNotBearer = errors.New("not a bearer token")
-//var ErrNotMyKID = errors.New("not my kid")
+
+// TimeError is returned when a Token is rejected because of one of its timestamps.
+// Use errors.Is to check for ErrExpired, ErrNotYetValid, or ErrTooOld.
+type TimeError struct {
+	Err   error     // ErrExpired, ErrNotYetValid, or ErrTooOld
+	Claim string    // name of the claim that was checked: "exp", "nbf", or "iat"
+	Time  time.Time // value of the claim
+	Now   time.Time // time the Token was checked
+}
+
+// Error implements the error interface.
+func (e *TimeError) Error() string {
+	return fmt.Sprintf("%v: %s %s, now %s", e.Err, e.Claim, e.Time.UTC().Format(time.RFC3339), e.Now.UTC().Format(time.RFC3339))
+}
+
+// Unwrap returns the underlying error.
+func (e *TimeError) Unwrap() error {
+	return e.Err
+}
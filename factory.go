@@ -24,42 +24,105 @@ SOFTWARE.
 package jsonwt
 
 import (
+	"crypto/hmac"
 	"encoding/json"
 	"time"
 )
 
 // NewFactory returns an initialized factory.
 // The signer is used to sign the generated tokens.
-// Factories are cheap, so create a new one to rotate keys.
+// If the signer also implements the Verifier interface, it is used to validate tokens.
+// Otherwise, the signer re-signs the message and the signatures are compared in constant time.
+// Factories are cheap, but a new factory can't validate tokens signed by the old one.
+// Use a Keyring to rotate keys.
 func NewFactory(kid string, s Signer) *Factory {
-	return &Factory{kid: kid, s: s}
+	f := &Factory{kid: kid, s: s}
+	if v, ok := s.(Verifier); ok {
+		f.v = v
+	} else if s != nil {
+		f.v = signerVerifier{s: s}
+	}
+	return f
+}
+
+// NewKeyringFactory returns a factory that uses the keyring's active signing key to sign tokens.
+// Tokens are validated with the key on the keyring that matches the header's "kid".
+// Changes to the keyring are seen immediately by the factory.
+func NewKeyringFactory(kr *Keyring) *Factory {
+	if kr == nil {
+		return &Factory{}
+	}
+	return &Factory{keys: kr}
+}
+
+// NewKeySetFactory returns a factory that can only validate tokens.
+// Tokens are validated with the key in the set that matches the header's "kid".
+func NewKeySetFactory(ks KeySet) *Factory {
+	if ks == nil {
+		return &Factory{}
+	}
+	return &Factory{keys: ks}
+}
+
+// NewVerifierFactory returns a factory that can only validate tokens.
+// This is useful for algorithms where the validator holds a public key
+// and does not have access to the secret used to sign tokens.
+func NewVerifierFactory(kid string, v Verifier) *Factory {
+	return &Factory{kid: kid, v: v}
 }
 
 type Factory struct {
-	kid string
-	s   Signer
+	kid    string
+	s      Signer
+	v      Verifier
+	keys   KeySet        // when set, supplies the keys instead of kid, s, and v
+	leeway time.Duration // allowance for clock skew when checking timestamps
+	clock  Clock         // source of the current time, nil for the system clock
 }
 
 // ID returns the id of the current signer.
 func (f *Factory) ID() string {
+	if kr, ok := f.keys.(*Keyring); ok {
+		kid, _ := kr.Signer()
+		return kid
+	}
 	return f.kid
 }
 
+// SetClock sets the source of the current time for the tokens created or validated by the factory.
+// It should be called before the factory is shared between goroutines.
+func (f *Factory) SetClock(clock Clock) {
+	f.clock = clock
+}
+
+// SetLeeway sets the allowance for clock skew between hosts.
+// Tokens validated by the factory are accepted up to leeway after "exp" and up to leeway before "nbf" and "iat".
+// It should be called before the factory is shared between goroutines.
+func (f *Factory) SetLeeway(leeway time.Duration) {
+	if leeway < 0 {
+		leeway = 0
+	}
+	f.leeway = leeway
+}
+
 // Sign will sign a Token.
 // It uses the current values in the header and payload, so it is safe to call multiple times.
 // It updates the Token's Algorithm field to match the factory's signer's algorithm.
 // It updates the Token's KeyID field to match the factory's key id.
 func (f *Factory) Sign(t *Token) error {
+	if t == nil {
+		return ErrInvalid
+	}
+
 	t.isSigned = false // unset the signed flag, just to be safe
 
-	if f == nil || f.kid == "" || f.s == nil {
-		return ErrBadFactory
-	} else if t == nil {
-		return ErrInvalid
+	kid, s, err := f.signer()
+	if err != nil {
+		return err
 	}
 
-	t.h.Algorithm = f.s.Algorithm()
-	t.h.KeyID = f.kid
+	t.h.Algorithm = s.Algorithm()
+	t.h.KeyID = kid
 
 	// base64 encode JSON representation of header
 	h, err := json.Marshal(t.h)
@@ -69,14 +132,18 @@ func (f *Factory) Sign(t *Token) error {
 	t.h.b64 = encode(h)
 
 	// base64 encode JSON representation of payload
-	p, err := json.Marshal(t.p)
+	p, err := t.marshalPayload()
 	if err != nil {
 		return err
 	}
 	t.p.b64 = encode(p)
+	t.p.members = nil
+	if err = json.Unmarshal(p, &t.p.members); err != nil {
+		return err
+	}
 
 	// base64 encode JSON representation of signature
-	rawSignature, err := f.s.Sign([]byte(t.h.b64 + "." + t.p.b64))
+	rawSignature, err := s.Sign([]byte(t.h.b64 + "." + t.p.b64))
 	if err != nil {
 		return err
 	}
@@ -90,11 +157,11 @@ func (f *Factory) Sign(t *Token) error {
 // Token is a helper to create a new, signed Token.
 // `claim` is the private application payload to add to the Token
 func (f *Factory) Token(ttl time.Duration, claim interface{}) (*Token, error) {
-	if f == nil || f.kid == "" || f.s == nil {
-		return nil, ErrBadFactory
+	if _, _, err := f.signer(); err != nil {
+		return nil, err
 	}
 
-	t, err := NewToken(ttl, claim)
+	t, err := newToken(f.getClock(), ttl, claim)
 	if err != nil {
 		return nil, err
 	} else if err = f.Sign(t); err != nil {
@@ -106,26 +173,104 @@ func (f *Factory) Token(ttl time.Duration, claim interface{}) (*Token, error) {
 
 // Validate will return an error if the Token is not properly signed.
 // It tries to update the isSigned to true only if the Token is properly signed.
-func (f *Factory) Validate(t *Token) error {
+// It does not check the timestamps; use Token.Validate for that.
+// If policies are given, the Token must also satisfy each of them.
+// A properly signed Token will use the factory's clock and leeway when checking its timestamps.
+// A signature that is not valid base64 is rejected with ErrInvalidSignature without being compared.
+// A Token whose header "kid" is not known to the factory is rejected with ErrNotMyKID.
+// A Token whose header "alg" is not the algorithm of that key is rejected with ErrNotMyAlgorithm.
+func (f *Factory) Validate(t *Token, policies ...*ValidationPolicy) error {
 	if t == nil {
 		return ErrInvalid
 	}
 
 	t.isSigned = false // unset the signed flag, just to be safe
 
-	if f == nil || f.kid == "" || f.s == nil {
-		return ErrBadFactory
+	// reject tokens that we did not sign, including "alg: none", before looking at the signature
+	v, err := f.verifier(t.h.KeyID)
+	if err != nil {
+		return err
+	} else if t.h.Algorithm != v.Algorithm() {
+		return ErrNotMyAlgorithm
 	}
 
-	expectedSignature, err := f.s.Sign([]byte(t.h.b64 + "." + t.p.b64))
+	sig, err := decodeSignature(t.s)
 	if err != nil {
 		return err
+	} else if err = v.Verify([]byte(t.h.b64+"."+t.p.b64), sig); err != nil {
+		return ErrUnauthorized
 	}
 
-	t.isSigned = t.s == encode(expectedSignature)
-	if !t.isSigned {
-		return ErrUnauthorized
+	t.isSigned = true
+	t.leeway = f.leeway
+	t.clock = f.getClock()
+
+	for _, p := range policies {
+		if err = p.Check(t); err != nil {
+			t.isSigned = false
+			return err
+		}
 	}
 
 	return nil // valid signature
 }
+
+// getClock is a helper that returns the factory's clock or the system clock.
+func (f *Factory) getClock() Clock {
+	if f.clock == nil {
+		return systemClock{}
+	}
+	return f.clock
+}
+
+// signer is a helper that returns the key id and Signer used to sign tokens.
+func (f *Factory) signer() (string, Signer, error) {
+	if f == nil {
+		return "", nil, ErrBadFactory
+	} else if kr, ok := f.keys.(*Keyring); ok {
+		kid, s := kr.Signer()
+		if kid == "" || s == nil {
+			return "", nil, ErrBadFactory
+		}
+		return kid, s, nil
+	} else if f.keys != nil || f.kid == "" || f.s == nil {
+		return "", nil, ErrBadFactory
+	}
+	return f.kid, f.s, nil
+}
+
+// verifier is a helper that returns the Verifier for the key id.
+func (f *Factory) verifier(kid string) (Verifier, error) {
+	if f == nil {
+		return nil, ErrBadFactory
+	} else if f.keys != nil {
+		return f.keys.Verifier(kid)
+	} else if f.kid == "" || f.v == nil {
+		return nil, ErrBadFactory
+	} else if kid != f.kid {
+		return nil, ErrNotMyKID
+	}
+	return f.v, nil
+}
+
+// signerVerifier adapts a Signer that does not implement the Verifier interface.
+// It verifies by signing the message again and comparing the signatures in constant time.
+type signerVerifier struct {
+	s Signer
+}
+
+// Algorithm implements the Verifier interface.
+func (sv signerVerifier) Algorithm() string {
+	return sv.s.Algorithm()
+}
+
+// Verify implements the Verifier interface.
+func (sv signerVerifier) Verify(msg, sig []byte) error {
+	expectedSignature, err := sv.s.Sign(msg)
+	if err != nil {
+		return err
+	} else if !hmac.Equal(sig, expectedSignature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
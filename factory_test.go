@@ -0,0 +1,201 @@
+/*******************************************************************************
+jsonwt - JSON Web Tokens
+Copyright (c) 2022 Michael D Henderson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+******************************************************************************/
+
+package jsonwt_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mdhender/jsonwt"
+	"github.com/mdhender/jsonwt/signers"
+)
+
+// testSecret is a 32-byte secret for the HS256 signers used in tests.
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+// countingVerifier counts the calls to Verify.
+type countingVerifier struct {
+	jsonwt.Verifier
+	calls int
+}
+
+func (cv *countingVerifier) Verify(msg, sig []byte) error {
+	cv.calls++
+	return cv.Verifier.Verify(msg, sig)
+}
+
+func TestValidateRejectsMalformedSignature(t *testing.T) {
+	hs := newHS256(t)
+	tok, err := jsonwt.NewFactory("k", hs).Token(time.Minute, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := tok.Signature()
+
+	// flip the lowest bit of the last character, which is padding for a 32-byte signature
+	const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+	last := strings.IndexByte(alphabet, sig[len(sig)-1])
+	nonCanonical := sig[:len(sig)-1] + string(alphabet[last^1])
+
+	for _, tc := range []struct {
+		name string
+		sig  string
+	}{
+		{"padded", sig + "="},
+		{"standard alphabet", "+" + sig[1:]},
+		{"non-canonical", nonCanonical},
+		{"truncated", sig[:len(sig)-2]},
+		{"invalid characters", "!!!!"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cv := &countingVerifier{Verifier: hs}
+			f := jsonwt.NewVerifierFactory("k", cv)
+			decoded := decodeWithSignature(t, tok, tc.sig)
+			if err := f.Validate(decoded); !errors.Is(err, jsonwt.ErrInvalidSignature) {
+				t.Errorf("got %v, want %v", err, jsonwt.ErrInvalidSignature)
+			} else if cv.calls != 0 {
+				t.Errorf("verify: got %d calls, want 0", cv.calls)
+			}
+		})
+	}
+
+	// the original signature must still be accepted
+	cv := &countingVerifier{Verifier: hs}
+	if err = jsonwt.NewVerifierFactory("k", cv).Validate(decodeWithSignature(t, tok, sig)); err != nil {
+		t.Errorf("valid: got %v, want nil", err)
+	} else if cv.calls != 1 {
+		t.Errorf("valid: got %d calls, want 1", cv.calls)
+	}
+}
+
+func TestValidateRejectsForeignHeader(t *testing.T) {
+	f := jsonwt.NewFactory("k", newHS256(t))
+	tok, err := f.Token(time.Minute, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the payload and signature of a real token, so only the header is wrong
+	_, rest, _ := strings.Cut(tok.String(), ".")
+	for _, tc := range []struct {
+		name   string
+		header string
+		err    error
+	}{
+		{"alg none", `{"alg":"none","typ":"JWT","kid":"k"}`, jsonwt.ErrNotMyAlgorithm},
+		{"alg NONE", `{"alg":"NONE","typ":"JWT","kid":"k"}`, jsonwt.ErrNotMyAlgorithm},
+		{"alg missing", `{"typ":"JWT","kid":"k"}`, jsonwt.ErrNotMyAlgorithm},
+		{"alg lower case", `{"alg":"hs256","typ":"JWT","kid":"k"}`, jsonwt.ErrNotMyAlgorithm},
+		{"alg other HMAC", `{"alg":"HS512","typ":"JWT","kid":"k"}`, jsonwt.ErrNotMyAlgorithm},
+		{"alg asymmetric", `{"alg":"RS256","typ":"JWT","kid":"k"}`, jsonwt.ErrNotMyAlgorithm},
+		{"kid other", `{"alg":"HS256","typ":"JWT","kid":"other"}`, jsonwt.ErrNotMyKID},
+		{"kid missing", `{"alg":"HS256","typ":"JWT"}`, jsonwt.ErrNotMyKID},
+		{"kid and alg", `{"alg":"none","typ":"JWT","kid":"other"}`, jsonwt.ErrNotMyKID},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data := base64.RawURLEncoding.EncodeToString([]byte(tc.header)) + "." + rest
+			decoded, err := jsonwt.Decode(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = f.Validate(decoded); !errors.Is(err, tc.err) {
+				t.Errorf("got %v, want %v", err, tc.err)
+			} else if err = decoded.Validate(); !errors.Is(err, jsonwt.ErrUnsigned) {
+				t.Errorf("token: got %v, want %v", err, jsonwt.ErrUnsigned)
+			}
+		})
+	}
+
+	// an unsecured token (RFC 7519 section 6) has an empty signature and is not decoded
+	payload, _, _ := strings.Cut(rest, ".")
+	none := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT","kid":"k"}`)) + "." + payload + "."
+	if _, err = jsonwt.Decode(none); !errors.Is(err, jsonwt.ErrBadToken) {
+		t.Errorf("unsecured: got %v, want %v", err, jsonwt.ErrBadToken)
+	}
+}
+
+func BenchmarkValidate(b *testing.B) {
+	hs, err := signers.NewHS256(testSecret)
+	if err != nil {
+		b.Fatal(err)
+	}
+	f := jsonwt.NewFactory("k", hs)
+	tok, err := f.Token(time.Minute, map[string]string{"name": "bench"})
+	if err != nil {
+		b.Fatal(err)
+	}
+	valid, err := jsonwt.Decode(tok.String())
+	if err != nil {
+		b.Fatal(err)
+	}
+	// change the first character of the signature so that it is compared and rejected
+	s := tok.String()
+	sigStart := strings.LastIndexByte(s, '.') + 1
+	c := byte('A')
+	if s[sigStart] == c {
+		c = 'B'
+	}
+	tampered, err := jsonwt.Decode(s[:sigStart] + string(c) + s[sigStart+1:])
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("valid", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := f.Validate(valid); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("tampered", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := f.Validate(tampered); !errors.Is(err, jsonwt.ErrUnauthorized) {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// decodeWithSignature is a helper that decodes the token with its signature replaced.
+func decodeWithSignature(t *testing.T, tok *jsonwt.Token, sig string) *jsonwt.Token {
+	t.Helper()
+	s := tok.String()
+	decoded, err := jsonwt.Decode(s[:strings.LastIndexByte(s, '.')+1] + sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return decoded
+}
+
+// newHS256 is a helper that returns an HS256 signer using the test secret.
+func newHS256(t *testing.T) *signers.HS256 {
+	t.Helper()
+	hs, err := signers.NewHS256(testSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hs
+}
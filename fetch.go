@@ -24,48 +24,111 @@ SOFTWARE.
 package jsonwt
 
 import (
+	"fmt"
 	"net/http"
 	"strings"
 )
 
-// FromBearerToken returns the Token from the Authorization header.
-// If there is no bearer token or if the token is invalid for any reason, it returns nil.
-func FromBearerToken(r *http.Request) *Token {
-	//log.Printf("jsonwt: bearer: entered\n")
-	headerAuthText := r.Header.Get("Authorization")
-	if headerAuthText == "" {
-		return nil
+// ExtractOptions configures how tokens are extracted from requests.
+// The zero value only accepts the Authorization header and, for ExtractToken, the cookie.
+type ExtractOptions struct {
+	// AllowFormParameter accepts a token in the "access_token" parameter of a
+	// form-encoded request body (RFC 6750 section 2.2).
+	AllowFormParameter bool
+	// AllowQueryParameter accepts a token in the "access_token" parameter of the
+	// URL's query (RFC 6750 section 2.3). This is not recommended since URLs are often logged.
+	AllowQueryParameter bool
+	// Cookie describes the cookie that ExtractToken checks.
+	// If it is nil, the DefaultCookieConfig is used.
+	Cookie *CookieConfig
+}
+
+// ExtractBearerToken returns the bearer token from the request (RFC 6750).
+// The "Bearer" scheme of the Authorization header is matched without regard to case.
+// It returns ErrMissingAuthHeader if the request has no token, ErrNotBearer if the Authorization
+// header uses a different scheme, ErrBadRequest if the request has more than one token,
+// or an error wrapping ErrMalformedToken if the token can't be decoded.
+// If opts is nil, only the Authorization header is checked.
+func ExtractBearerToken(r *http.Request, opts *ExtractOptions) (*Token, error) {
+	if opts == nil {
+		opts = &ExtractOptions{}
 	}
-	//log.Printf("jsonwt: bearer: found authorization header\n")
-	authTokens := strings.SplitN(headerAuthText, " ", 2)
-	if len(authTokens) != 2 {
-		return nil
+
+	var found []string
+	if headerAuthText := r.Header.Get("Authorization"); headerAuthText != "" {
+		authType, authToken, _ := strings.Cut(strings.TrimSpace(headerAuthText), " ")
+		if !strings.EqualFold(authType, "Bearer") {
+			return nil, ErrNotBearer
+		}
+		found = append(found, strings.TrimSpace(authToken))
 	}
-	//log.Printf("jsonwt: bearer: found authorization token\n")
-	authType, authToken := authTokens[0], strings.TrimSpace(authTokens[1])
-	if authType != "Bearer" {
-		return nil
+	if opts.AllowFormParameter && r.Method != http.MethodGet && isFormEncoded(r) {
+		if err := r.ParseForm(); err != nil {
+			return nil, ErrBadRequest
+		} else if values, ok := r.PostForm["access_token"]; ok {
+			found = append(found, values...)
+		}
+	}
+	if opts.AllowQueryParameter {
+		if values, ok := r.URL.Query()["access_token"]; ok {
+			found = append(found, values...)
+		}
+	}
+
+	if len(found) == 0 {
+		return nil, ErrMissingAuthHeader
+	} else if len(found) != 1 {
+		return nil, ErrBadRequest // clients must not use more than one method
 	}
-	//log.Printf("jsonwt: bearer: found bearer token\n")
-	j, err := Decode(authToken)
+	t, err := Decode(found[0])
 	if err != nil {
-		//log.Printf("jsonwt: bearer: token: %+v\n", err)
-		return nil
+		return nil, fmt.Errorf("%w: %v", ErrMalformedToken, err)
 	}
-	//log.Printf("jsonwt: bearer: returning bearer token\n")
-	return j
+	return t, nil
 }
 
-func FromCookie(r *http.Request) *Token {
-	//log.Printf("jsonwt: cookie: entered\n")
-	c, err := r.Cookie("jwt")
+// ExtractToken returns the Token from the request.
+// It looks for a bearer token first (see ExtractBearerToken).
+// If it can't find one, it looks for a cookie.
+// It returns ErrMissingToken if the request has neither, or ErrNotBearer if
+// the request has no cookie and an Authorization header with a different scheme.
+func ExtractToken(r *http.Request, opts *ExtractOptions) (*Token, error) {
+	t, bearerErr := ExtractBearerToken(r, opts)
+	if bearerErr == nil {
+		return t, nil
+	} else if bearerErr != ErrMissingAuthHeader && bearerErr != ErrNotBearer {
+		return nil, bearerErr
+	}
+	cookies := DefaultCookieConfig
+	if opts != nil && opts.Cookie != nil {
+		cookies = opts.Cookie
+	}
+	t, err := cookies.Extract(r)
+	if err == ErrMissingToken && bearerErr == ErrNotBearer {
+		return nil, ErrNotBearer
+	} else if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// FromBearerToken returns the Token from the Authorization header.
+// If there is no bearer token or if the token is invalid for any reason, it returns nil.
+// Use ExtractBearerToken to find out why there is no Token.
+func FromBearerToken(r *http.Request) *Token {
+	t, err := ExtractBearerToken(r, nil)
 	if err != nil {
-		//log.Printf("jsonwt: cookie: %+v\n", err)
 		return nil
 	}
-	t, err := Decode(c.Value)
+	return t
+}
+
+// FromCookie returns the Token from the cookie described by the DefaultCookieConfig.
+// If there is no cookie or if the token is invalid for any reason, it returns nil.
+func FromCookie(r *http.Request) *Token {
+	t, err := DefaultCookieConfig.Extract(r)
 	if err != nil {
-		//log.Printf("jsonwt: cookie: token: %+v\n", err)
+		//log.Printf("jsonwt: cookie: %+v\n", err)
 		return nil
 	}
 	return t
@@ -81,3 +144,9 @@ func FromRequest(r *http.Request) *Token {
 	}
 	return t
 }
+
+// isFormEncoded is a helper function that returns true if the request body is form-encoded.
+func isFormEncoded(r *http.Request) bool {
+	contentType, _, _ := strings.Cut(r.Header.Get("Content-Type"), ";")
+	return strings.EqualFold(strings.TrimSpace(contentType), "application/x-www-form-urlencoded")
+}
@@ -0,0 +1,133 @@
+/*******************************************************************************
+jsonwt - JSON Web Tokens
+Copyright (c) 2022 Michael D Henderson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+******************************************************************************/
+
+package jsonwt_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mdhender/jsonwt"
+)
+
+func TestExtractBearerToken(t *testing.T) {
+	tok, err := jsonwt.NewFactory("k", newHS256(t)).Token(time.Minute, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := tok.String()
+	query := "/?access_token=" + url.QueryEscape(s)
+	form := func(method, target string) *http.Request {
+		r := httptest.NewRequest(method, target, strings.NewReader("access_token="+url.QueryEscape(s)))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+		return r
+	}
+	withHeader := func(r *http.Request, auth string) *http.Request {
+		r.Header.Set("Authorization", auth)
+		return r
+	}
+	all := &jsonwt.ExtractOptions{AllowFormParameter: true, AllowQueryParameter: true}
+
+	for _, tc := range []struct {
+		name string
+		r    *http.Request
+		opts *jsonwt.ExtractOptions
+		err  error
+	}{
+		{"header", bearerRequest(s), nil, nil},
+		{"lower case scheme", withHeader(httptest.NewRequest(http.MethodGet, "/", nil), "bearer "+s), nil, nil},
+		{"upper case scheme", withHeader(httptest.NewRequest(http.MethodGet, "/", nil), "BEARER "+s), nil, nil},
+		{"missing", httptest.NewRequest(http.MethodGet, "/", nil), all, jsonwt.ErrMissingAuthHeader},
+		{"other scheme", withHeader(httptest.NewRequest(http.MethodGet, "/", nil), "Basic YmlsYm86YmFnZ2lucw=="), nil, jsonwt.ErrNotBearer},
+		{"malformed", bearerRequest("not-a-token"), nil, jsonwt.ErrMalformedToken},
+		{"form", form(http.MethodPost, "/"), all, nil},
+		{"form not allowed", form(http.MethodPost, "/"), nil, jsonwt.ErrMissingAuthHeader},
+		{"form on GET", form(http.MethodGet, "/"), all, jsonwt.ErrMissingAuthHeader},
+		{"query", httptest.NewRequest(http.MethodGet, query, nil), all, nil},
+		{"query not allowed", httptest.NewRequest(http.MethodGet, query, nil), nil, jsonwt.ErrMissingAuthHeader},
+		{"header and query", withHeader(httptest.NewRequest(http.MethodGet, query, nil), "Bearer "+s), all, jsonwt.ErrBadRequest},
+		{"header and form", withHeader(form(http.MethodPost, "/"), "Bearer "+s), all, jsonwt.ErrBadRequest},
+		{"form and query", form(http.MethodPost, query), all, jsonwt.ErrBadRequest},
+		{"two query parameters", httptest.NewRequest(http.MethodGet, query+"&access_token=x", nil), all, jsonwt.ErrBadRequest},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := jsonwt.ExtractBearerToken(tc.r, tc.opts)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("got %v, want %v", err, tc.err)
+			} else if err == nil && got.String() != s {
+				t.Errorf("token: got %q, want %q", got.String(), s)
+			}
+		})
+	}
+}
+
+func TestMiddlewareRejectsMultipleMethods(t *testing.T) {
+	f := jsonwt.NewFactory("k", newHS256(t))
+	tok, err := f.Token(time.Minute, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := bearerRequest(tok.String())
+	r.URL.RawQuery = "access_token=" + url.QueryEscape(tok.String())
+	opts := &jsonwt.MiddlewareOptions{Extract: &jsonwt.ExtractOptions{AllowQueryParameter: true}}
+	w, _, called := serve(jsonwt.Middleware(f, opts), r)
+	want := `Bearer error="invalid_request", error_description="more than one token in the request"`
+	if w.Code != http.StatusBadRequest || called {
+		t.Errorf("code: got %d, want %d", w.Code, http.StatusBadRequest)
+	} else if got := w.Header().Get("WWW-Authenticate"); got != want {
+		t.Errorf("challenge: got %q, want %q", got, want)
+	}
+}
+
+func TestChallenge(t *testing.T) {
+	for _, tc := range []struct {
+		c    jsonwt.Challenge
+		want string
+	}{
+		{jsonwt.Challenge{}, "Bearer"},
+		{jsonwt.Challenge{Realm: "example"}, `Bearer realm="example"`},
+		{
+			jsonwt.Challenge{Realm: "example", Scope: "read write", Error: jsonwt.ErrorCodeInsufficientScope, ErrorDescription: "needs write"},
+			`Bearer realm="example", scope="read write", error="insufficient_scope", error_description="needs write"`,
+		},
+		// characters that RFC 6750 doesn't allow are removed
+		{jsonwt.Challenge{ErrorDescription: "say \"hi\"\\\né"}, `Bearer error_description="say hi"`},
+	} {
+		if got := tc.c.String(); got != tc.want {
+			t.Errorf("got %q, want %q", got, tc.want)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	jsonwt.WriteChallenge(w, http.StatusUnauthorized, jsonwt.Challenge{Error: jsonwt.ErrorCodeInvalidToken})
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("code: got %d, want %d", w.Code, http.StatusUnauthorized)
+	} else if got := w.Header().Get("WWW-Authenticate"); got != `Bearer error="invalid_token"` {
+		t.Errorf("header: got %q, want %q", got, `Bearer error="invalid_token"`)
+	}
+}
@@ -34,11 +34,19 @@ import (
 // `claim` is the optional private payload for use by the application.
 // If provided, claim will be marshalled to JSON, then base64 encoded.
 func NewToken(ttl time.Duration, claim interface{}) (*Token, error) {
+	return newToken(systemClock{}, ttl, claim)
+}
+
+// newToken is a helper to create an unsigned Token using the clock for the timestamps.
+// The Token will use the clock when it is validated.
+func newToken(clock Clock, ttl time.Duration, claim interface{}) (*Token, error) {
 	var t Token
 	t.h.Version = 1
 	t.h.TokenType = "JWT"
-	t.p.IssuedAt = time.Now().Unix()
-	t.p.ExpirationTime = time.Now().Add(ttl).Unix()
+	now := clock.Now()
+	t.p.IssuedAt = NewNumericDate(now)
+	t.p.ExpirationTime = NewNumericDate(now.Add(ttl))
+	t.clock = clock
 	if claim != nil { // claim is optional.
 		b, err := json.Marshal(claim)
 		if err != nil {
@@ -52,12 +60,13 @@ func NewToken(ttl time.Duration, claim interface{}) (*Token, error) {
 // Token implements my version of the JSON Web Token.
 type Token struct {
 	h struct {
-		Version     int    `json:"ver,omitempty"`
-		Algorithm   string `json:"alg"` // message authentication code algorithm
-		TokenType   string `json:"typ"` // should always be JWT
-		KeyID       string `json:"kid"` // identifier used to sign
-		ContentType string `json:"cty,omitempty"`
-		b64         string // header marshalled to JSON and then base-64 encoded
+		Version     int      `json:"ver,omitempty"`
+		Algorithm   string   `json:"alg"` // message authentication code algorithm
+		TokenType   string   `json:"typ"` // should always be JWT
+		KeyID       string   `json:"kid"` // identifier used to sign
+		ContentType string   `json:"cty,omitempty"`
+		Critical    []string `json:"crit,omitempty"` // extensions that must be understood
+		b64         string   // header marshalled to JSON and then base-64 encoded
 	}
 	p struct {
 		// The principal that issued the Token.
@@ -68,22 +77,30 @@ type Token struct {
 		// Each principal intended to process the Token must identify itself with a value in the audience claim.
 		// If the principal processing the claim does not identify itself with a value in the aud claim when this claim is present,
 		// then the Token must be rejected.
-		Audience []string `json:"aud,omitempty"`
+		Audience Audience `json:"aud,omitempty"`
 		// The expiration time on and after which the Token must not be accepted for processing.
 		// The value must be a NumericDate:[9] either an integer or decimal, representing seconds past 1970-01-01 00:00:00Z.
-		ExpirationTime int64 `json:"exp,omitempty"`
+		ExpirationTime NumericDate `json:"exp,omitempty"`
 		// The time on which the Token will start to be accepted for processing.
 		// The value must be a NumericDate.
-		NotBefore int64 `json:"nbf,omitempty"`
+		NotBefore NumericDate `json:"nbf,omitempty"`
 		// The time at which the Token was issued.
 		// The value must be a NumericDate.
-		IssuedAt int64 `json:"iat,omitempty"`
+		IssuedAt NumericDate `json:"iat,omitempty"`
 		// Case sensitive unique identifier of the token even among different issuers.
 		JWTID string `json:"jti,omitempty"`
 		// Claim is private data for use by the application.
 		Claim string `json:"claim,omitempty"`
-		b64   string // payload marshalled to JSON and then base-64 encoded
+		// private holds the private claims that are top-level members of the payload.
+		private map[string]json.RawMessage
+		// singleAudience encodes the audience as a string instead of an array.
+		singleAudience bool
+		// members holds every member of the payload; it is set when the Token is decoded or signed.
+		members map[string]json.RawMessage
+		b64     string // payload marshalled to JSON and then base-64 encoded
 	}
-	s        string // signature base-64 encoded
-	isSigned bool   // true only if the signature has been verified
+	s        string        // signature base-64 encoded
+	isSigned bool          // true only if the signature has been verified
+	leeway   time.Duration // allowance for clock skew when checking timestamps
+	clock    Clock         // source of the current time, nil for the system clock
 }
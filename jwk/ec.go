@@ -0,0 +1,176 @@
+/*
+ * jsonwt - JSON Web Tokens
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package jwk
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"math/big"
+
+	"github.com/mdhender/jsonwt"
+	"github.com/mdhender/jsonwt/signers"
+)
+
+// fromEC is a helper function that returns the JSON Web Key for an ECDSA public key.
+// The coordinates are left-padded to the size of the curve, as required by RFC 7518.
+func fromEC(pub *ecdsa.PublicKey) (*Key, error) {
+	if pub == nil || pub.X == nil || pub.Y == nil {
+		return nil, ErrInvalidKey
+	}
+	crv, _, ok := curveName(pub.Curve)
+	if !ok {
+		return nil, ErrUnsupportedKeyType
+	}
+	size := curveSize(pub.Curve)
+	x, y := make([]byte, size), make([]byte, size)
+	pub.X.FillBytes(x)
+	pub.Y.FillBytes(y)
+	return &Key{KeyType: "EC", Curve: crv, X: encode(x), Y: encode(y)}, nil
+}
+
+// ecPublicKey is a helper that returns the public key from an EC JSON Web Key.
+func (k *Key) ecPublicKey() (*ecdsa.PublicKey, error) {
+	curve, ok := curveFromName(k.Curve)
+	if !ok {
+		return nil, ErrUnsupportedKeyType
+	}
+	x, err := decode(k.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := decode(k.Y)
+	if err != nil {
+		return nil, err
+	} else if len(x) != curveSize(curve) || len(y) != curveSize(curve) {
+		return nil, ErrInvalidKey
+	}
+	pub := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+	if !curve.IsOnCurve(pub.X, pub.Y) {
+		return nil, ErrInvalidKey
+	}
+	return pub, nil
+}
+
+// ecAlgorithm is a helper that returns the algorithm for an EC JSON Web Key.
+// If the key does not specify one, it is derived from the curve.
+func (k *Key) ecAlgorithm(curve elliptic.Curve) (string, error) {
+	_, alg, _ := curveName(curve)
+	if k.Algorithm == "" {
+		return alg, nil
+	} else if k.Algorithm != alg {
+		return "", ErrUnsupportedAlgorithm
+	}
+	return alg, nil
+}
+
+// ecSigner is a helper that returns the signer for an EC JSON Web Key.
+func (k *Key) ecSigner() (jsonwt.Signer, error) {
+	pub, err := k.ecPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	d, err := decode(k.D)
+	if err != nil {
+		return nil, err
+	} else if len(d) != curveSize(pub.Curve) {
+		return nil, ErrInvalidKey
+	}
+	key := &ecdsa.PrivateKey{PublicKey: *pub, D: new(big.Int).SetBytes(d)}
+	if x, y := pub.Curve.ScalarBaseMult(d); x.Cmp(pub.X) != 0 || y.Cmp(pub.Y) != 0 {
+		return nil, ErrInvalidKey
+	}
+	alg, err := k.ecAlgorithm(pub.Curve)
+	if err != nil {
+		return nil, err
+	}
+	var s jsonwt.Signer
+	switch alg {
+	case "ES256":
+		s, err = signers.NewES256(key)
+	case "ES384":
+		s, err = signers.NewES384(key)
+	case "ES512":
+		s, err = signers.NewES512(key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ecVerifier is a helper that returns the verifier for an EC JSON Web Key.
+func (k *Key) ecVerifier() (jsonwt.Verifier, error) {
+	pub, err := k.ecPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	alg, err := k.ecAlgorithm(pub.Curve)
+	if err != nil {
+		return nil, err
+	}
+	var v jsonwt.Verifier
+	switch alg {
+	case "ES256":
+		v, err = signers.NewES256Verifier(pub)
+	case "ES384":
+		v, err = signers.NewES384Verifier(pub)
+	case "ES512":
+		v, err = signers.NewES512Verifier(pub)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// curveFromName is a helper function that returns the curve for the JWK "crv" value.
+func curveFromName(name string) (elliptic.Curve, bool) {
+	switch name {
+	case "P-256":
+		return elliptic.P256(), true
+	case "P-384":
+		return elliptic.P384(), true
+	case "P-521":
+		return elliptic.P521(), true
+	}
+	return nil, false
+}
+
+// curveName is a helper function that returns the JWK "crv" value and the JWS algorithm for the curve.
+func curveName(curve elliptic.Curve) (crv, alg string, ok bool) {
+	switch curve {
+	case elliptic.P256():
+		return "P-256", "ES256", true
+	case elliptic.P384():
+		return "P-384", "ES384", true
+	case elliptic.P521():
+		return "P-521", "ES512", true
+	}
+	return "", "", false
+}
+
+// curveSize is a helper function that returns the number of bytes needed to hold a coordinate on the curve.
+func curveSize(curve elliptic.Curve) int {
+	return (curve.Params().BitSize + 7) / 8
+}
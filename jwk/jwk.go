@@ -0,0 +1,176 @@
+/*
+ * jsonwt - JSON Web Tokens
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+// Package jwk implements JSON Web Keys (RFC 7517) for the jsonwt signers.
+package jwk
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+
+	"github.com/mdhender/jsonwt"
+)
+
+// Key is a JSON Web Key.
+// Binary members are stored as the base64url encoded strings from the JSON.
+type Key struct {
+	KeyType   string   `json:"kty"`
+	Use       string   `json:"use,omitempty"`
+	KeyOps    []string `json:"key_ops,omitempty"`
+	Algorithm string   `json:"alg,omitempty"`
+	KeyID     string   `json:"kid,omitempty"`
+	// RSA keys
+	N  string `json:"n,omitempty"`
+	E  string `json:"e,omitempty"`
+	P  string `json:"p,omitempty"`
+	Q  string `json:"q,omitempty"`
+	DP string `json:"dp,omitempty"`
+	DQ string `json:"dq,omitempty"`
+	QI string `json:"qi,omitempty"`
+	// EC and OKP keys
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	Y     string `json:"y,omitempty"`
+	// private key for RSA, EC, and OKP keys
+	D string `json:"d,omitempty"`
+	// symmetric keys
+	K string `json:"k,omitempty"`
+}
+
+// New returns the JSON Web Key for the verifier.
+// Asymmetric keys contain only the public key.
+// HMAC keys contain the secret, so they must never be published.
+func New(kid string, v jsonwt.Verifier) (*Key, error) {
+	if kid == "" {
+		return nil, ErrMissingKID
+	}
+
+	var k *Key
+	var err error
+	switch vk := v.(type) {
+	case interface{ Public() crypto.PublicKey }:
+		switch pub := vk.Public().(type) {
+		case *rsa.PublicKey:
+			k, err = fromRSA(pub)
+		case *ecdsa.PublicKey:
+			k, err = fromEC(pub)
+		case ed25519.PublicKey:
+			k, err = fromOKP(pub)
+		default:
+			return nil, ErrUnsupportedKeyType
+		}
+	case interface{ Secret() []byte }:
+		k, err = fromOct(vk.Secret())
+	default:
+		return nil, ErrUnsupportedKeyType
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	k.Use = "sig"
+	k.Algorithm = v.Algorithm()
+	k.KeyID = kid
+
+	return k, nil
+}
+
+// IsPrivate returns true if the key contains private or secret key material.
+func (k *Key) IsPrivate() bool {
+	return k.D != "" || k.K != ""
+}
+
+// Public returns a copy of the key with the private and secret members removed.
+func (k *Key) Public() *Key {
+	pk := *k
+	pk.P, pk.Q, pk.DP, pk.DQ, pk.QI, pk.D, pk.K = "", "", "", "", "", "", ""
+	return &pk
+}
+
+// Signer returns a signer for the key.
+// The key must contain the private key (or the secret for HMAC keys).
+func (k *Key) Signer() (jsonwt.Signer, error) {
+	if !k.IsPrivate() {
+		return nil, ErrMissingPrivateKey
+	}
+	switch k.KeyType {
+	case "RSA":
+		return k.rsaSigner()
+	case "EC":
+		return k.ecSigner()
+	case "OKP":
+		return k.okpSigner()
+	case "oct":
+		return k.octSigner()
+	}
+	return nil, ErrUnsupportedKeyType
+}
+
+// Verifier returns a verifier for the key.
+func (k *Key) Verifier() (jsonwt.Verifier, error) {
+	switch k.KeyType {
+	case "RSA":
+		return k.rsaVerifier()
+	case "EC":
+		return k.ecVerifier()
+	case "OKP":
+		return k.okpVerifier()
+	case "oct":
+		s, err := k.octSigner()
+		if err != nil {
+			return nil, err
+		}
+		return s.(jsonwt.Verifier), nil
+	}
+	return nil, ErrUnsupportedKeyType
+}
+
+// decode is a helper function for converting a string containing the base64url representation to raw bytes.
+func decode(raw string) ([]byte, error) {
+	if raw == "" {
+		return nil, ErrInvalidKey
+	}
+	b, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	return b, nil
+}
+
+// decodeInt is a helper function for converting a string containing the base64url representation to a big integer.
+func decodeInt(raw string) (*big.Int, error) {
+	b, err := decode(raw)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// encode is a helper function for converting a slice of raw bytes to a string containing the base64url representation.
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
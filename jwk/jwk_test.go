@@ -0,0 +1,338 @@
+/*
+ * jsonwt - JSON Web Tokens
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package jwk_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mdhender/jsonwt"
+	"github.com/mdhender/jsonwt/jwk"
+)
+
+var msg = []byte("It's a dangerous business, Frodo, going out your door.")
+
+// privateKeys are the private keys from testdata along with the algorithm to use them with.
+var privateKeys = []struct {
+	name string
+	alg  string // set on keys that don't specify one
+}{
+	{"rfc7520-rsa.json", "PS256"},
+	{"rfc7520-ec.json", ""}, // P-521 with a left-padded "x" coordinate
+	{"rfc8037-okp.json", ""},
+	{"rfc7520-oct.json", ""},
+}
+
+// loadKey is a helper that unmarshals a JSON Web Key from testdata.
+func loadKey(t *testing.T, name string) *jwk.Key {
+	t.Helper()
+	data, err := os.ReadFile("../testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var k jwk.Key
+	if err = json.Unmarshal(data, &k); err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	return &k
+}
+
+// roundTrip is a helper that marshals the key to JSON and back.
+func roundTrip(t *testing.T, k *jwk.Key) *jwk.Key {
+	t.Helper()
+	data, err := json.Marshal(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rt jwk.Key
+	if err = json.Unmarshal(data, &rt); err != nil {
+		t.Fatal(err)
+	}
+	return &rt
+}
+
+func TestKeyRoundTrip(t *testing.T) {
+	for _, tc := range privateKeys {
+		t.Run(tc.name, func(t *testing.T) {
+			priv := loadKey(t, tc.name)
+			if tc.alg != "" {
+				priv.Algorithm = tc.alg
+			}
+			s, err := priv.Signer()
+			if err != nil {
+				t.Fatalf("signer: %v", err)
+			}
+			sig, err := s.Sign(msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// New must encode the same key that we started with
+			k, err := jwk.New("k1", s.(jsonwt.Verifier))
+			if err != nil {
+				t.Fatalf("new: %v", err)
+			} else if k.KeyType != priv.KeyType || k.Algorithm != s.Algorithm() || k.KeyID != "k1" || k.Use != "sig" {
+				t.Errorf("new: got kty %q alg %q kid %q use %q", k.KeyType, k.Algorithm, k.KeyID, k.Use)
+			}
+			if k.N != priv.N || k.E != priv.E || k.Curve != priv.Curve || k.X != priv.X || k.Y != priv.Y || k.K != priv.K {
+				t.Errorf("new: public members don't match testdata\n got %+v\nwant %+v", k, priv.Public())
+			}
+			if got, want := k.IsPrivate(), priv.KeyType == "oct"; got != want {
+				t.Errorf("new: private: got %v, want %v", got, want)
+			}
+
+			v, err := roundTrip(t, k).Verifier()
+			if err != nil {
+				t.Fatalf("verifier: %v", err)
+			} else if v.Algorithm() != s.Algorithm() {
+				t.Errorf("verifier: alg: got %q, want %q", v.Algorithm(), s.Algorithm())
+			}
+			if err = v.Verify(msg, sig); err != nil {
+				t.Errorf("verify: got %v, want nil", err)
+			}
+			sig[len(sig)-1] ^= 1
+			if err = v.Verify(msg, sig); err == nil {
+				t.Errorf("verify: tampered signature: got nil, want error")
+			}
+		})
+	}
+}
+
+func TestKeyVerifiesPaddedCoordinates(t *testing.T) {
+	// the ES512 vector from RFC 7520 section 4.3 was signed by the P-521 key in testdata
+	var vectors []struct {
+		Algorithm string `json:"alg"`
+		Input     string `json:"input"`
+		Signature string `json:"signature"`
+	}
+	data, err := os.ReadFile("../testdata/ecdsa.json")
+	if err != nil {
+		t.Fatal(err)
+	} else if err = json.Unmarshal(data, &vectors); err != nil {
+		t.Fatal(err)
+	}
+	k := loadKey(t, "rfc7520-ec.json").Public()
+	if x, _ := base64.RawURLEncoding.DecodeString(k.X); len(x) != 66 || x[0] != 0 {
+		t.Fatalf("x: want a 66 byte coordinate with a leading zero")
+	}
+	v, err := k.Verifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range vectors {
+		if tc.Algorithm != "ES512" {
+			continue
+		}
+		sig, err := base64.RawURLEncoding.DecodeString(tc.Signature)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = v.Verify([]byte(tc.Input), sig); err != nil {
+			t.Errorf("verify: got %v, want nil", err)
+		}
+		return
+	}
+	t.Fatal("missing ES512 vector")
+}
+
+func TestKeyPublic(t *testing.T) {
+	for _, tc := range privateKeys {
+		t.Run(tc.name, func(t *testing.T) {
+			priv := loadKey(t, tc.name)
+			if !priv.IsPrivate() {
+				t.Fatal("testdata: want a private key")
+			}
+			pub := priv.Public()
+			if pub.IsPrivate() {
+				t.Errorf("public: IsPrivate: got true, want false")
+			}
+			if !priv.IsPrivate() {
+				t.Errorf("public: modified the original key")
+			}
+			data, err := json.Marshal(pub)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var members map[string]any
+			if err = json.Unmarshal(data, &members); err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range []string{"d", "p", "q", "dp", "dq", "qi", "k"} {
+				if _, ok := members[name]; ok {
+					t.Errorf("public: %q was not removed", name)
+				}
+			}
+			if _, err = pub.Signer(); !errors.Is(err, jwk.ErrMissingPrivateKey) {
+				t.Errorf("signer: got %v, want %v", err, jwk.ErrMissingPrivateKey)
+			}
+		})
+	}
+}
+
+func TestSetPublic(t *testing.T) {
+	s := &jwk.Set{}
+	for _, tc := range privateKeys {
+		s.Keys = append(s.Keys, loadKey(t, tc.name))
+	}
+	ps := s.Public()
+	if len(ps.Keys) != len(s.Keys)-1 {
+		t.Errorf("public: got %d keys, want %d", len(ps.Keys), len(s.Keys)-1)
+	}
+	for _, k := range ps.Keys {
+		if k.KeyType == "oct" {
+			t.Errorf("public: oct key was not removed")
+		} else if k.IsPrivate() {
+			t.Errorf("public: %s key is private", k.KeyType)
+		}
+	}
+	for _, k := range s.Keys {
+		if !k.IsPrivate() {
+			t.Errorf("public: modified the original %s key", k.KeyType)
+		}
+	}
+}
+
+func TestFromKeyring(t *testing.T) {
+	kr := jsonwt.NewKeyring()
+	for kid, name := range map[string]string{"rsa": "rfc7520-rsa.json", "ec": "rfc7520-ec.json", "okp": "rfc8037-okp.json", "hs": "rfc7520-oct.json"} {
+		k := loadKey(t, name)
+		if k.KeyType == "RSA" {
+			k.Algorithm = "RS256"
+		}
+		s, err := k.Signer()
+		if err != nil {
+			t.Fatal(err)
+		} else if err = kr.SetSigner(kid, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s, err := jwk.FromKeyring(kr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{`"d"`, `"k"`, `"p"`, `"q"`} {
+		if strings.Contains(string(data), name) {
+			t.Errorf("json: contains %s member: %s", name, data)
+		}
+	}
+	if s.Key("hs") != nil {
+		t.Errorf("key set: contains the HMAC key")
+	}
+	ps, err := jwk.Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifiers, err := ps.Verifiers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, kid := range []string{"rsa", "ec", "okp"} {
+		if _, ok := verifiers[kid]; !ok {
+			t.Errorf("verifiers: missing %q", kid)
+		}
+	}
+	if len(verifiers) != 3 {
+		t.Errorf("verifiers: got %d, want 3", len(verifiers))
+	}
+}
+
+func TestKeyRejectsInvalidPublicKey(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		modify func(k *jwk.Key)
+	}{
+		{"off curve", func(k *jwk.Key) { k.Y = bump(t, k.Y) }},
+		{"unpadded x", func(k *jwk.Key) { k.X = strip(t, k.X) }},
+		{"x too long", func(k *jwk.Key) { k.X = pad(t, k.X) }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			k := loadKey(t, "rfc7520-ec.json")
+			tc.modify(k)
+			if _, err := k.Public().Verifier(); !errors.Is(err, jwk.ErrInvalidKey) {
+				t.Errorf("verifier: got %v, want %v", err, jwk.ErrInvalidKey)
+			}
+			if _, err := k.Signer(); !errors.Is(err, jwk.ErrInvalidKey) {
+				t.Errorf("signer: got %v, want %v", err, jwk.ErrInvalidKey)
+			}
+		})
+	}
+}
+
+func TestKeyRejectsMismatchedPrivateKey(t *testing.T) {
+	for _, tc := range privateKeys {
+		if tc.name == "rfc7520-oct.json" {
+			continue // a secret has no public key to match
+		}
+		t.Run(tc.name, func(t *testing.T) {
+			k := loadKey(t, tc.name)
+			if tc.alg != "" {
+				k.Algorithm = tc.alg
+			}
+			k.D = bump(t, k.D)
+			if _, err := k.Signer(); !errors.Is(err, jwk.ErrInvalidKey) {
+				t.Errorf("signer: got %v, want %v", err, jwk.ErrInvalidKey)
+			}
+		})
+	}
+}
+
+// bump is a helper that returns the base64url encoding of the value plus one, keeping its length.
+func bump(t *testing.T, raw string) string {
+	t.Helper()
+	b, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := new(big.Int).Add(new(big.Int).SetBytes(b), big.NewInt(1))
+	return base64.RawURLEncoding.EncodeToString(n.FillBytes(make([]byte, len(b))))
+}
+
+// strip is a helper that returns the base64url encoding of the value without its leading zero bytes.
+func strip(t *testing.T, raw string) string {
+	t.Helper()
+	b, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(new(big.Int).SetBytes(b).Bytes())
+}
+
+// pad is a helper that returns the base64url encoding of the value with an extra leading zero byte.
+func pad(t *testing.T, raw string) string {
+	t.Helper()
+	b, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(append([]byte{0}, b...))
+}
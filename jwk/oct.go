@@ -0,0 +1,63 @@
+/*
+ * jsonwt - JSON Web Tokens
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package jwk
+
+import (
+	"github.com/mdhender/jsonwt"
+	"github.com/mdhender/jsonwt/signers"
+)
+
+// fromOct is a helper function that returns the JSON Web Key for an HMAC secret.
+func fromOct(secret []byte) (*Key, error) {
+	if len(secret) == 0 {
+		return nil, ErrInvalidKey
+	}
+	return &Key{KeyType: "oct", K: encode(secret)}, nil
+}
+
+// octSigner is a helper that returns the signer for an oct JSON Web Key.
+// The signers enforce the minimum length of the secret.
+func (k *Key) octSigner() (jsonwt.Signer, error) {
+	secret, err := decode(k.K)
+	if err != nil {
+		return nil, err
+	}
+	var s jsonwt.Signer
+	switch k.Algorithm {
+	case "":
+		return nil, ErrMissingAlgorithm
+	case "HS256":
+		s, err = signers.NewHS256(secret)
+	case "HS384":
+		s, err = signers.NewHS384(secret)
+	case "HS512":
+		s, err = signers.NewHS512(secret)
+	default:
+		return nil, ErrUnsupportedAlgorithm
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
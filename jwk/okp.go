@@ -0,0 +1,93 @@
+/*
+ * jsonwt - JSON Web Tokens
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package jwk
+
+import (
+	"bytes"
+	"crypto/ed25519"
+
+	"github.com/mdhender/jsonwt"
+	"github.com/mdhender/jsonwt/signers"
+)
+
+// fromOKP is a helper function that returns the JSON Web Key for an Ed25519 public key (RFC 8037).
+func fromOKP(pub ed25519.PublicKey) (*Key, error) {
+	if len(pub) != ed25519.PublicKeySize {
+		return nil, ErrInvalidKey
+	}
+	return &Key{KeyType: "OKP", Curve: "Ed25519", X: encode(pub)}, nil
+}
+
+// okpPublicKey is a helper that returns the public key from an OKP JSON Web Key.
+func (k *Key) okpPublicKey() (ed25519.PublicKey, error) {
+	if k.Curve != "Ed25519" {
+		return nil, ErrUnsupportedKeyType
+	} else if k.Algorithm != "" && k.Algorithm != "EdDSA" {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	x, err := decode(k.X)
+	if err != nil {
+		return nil, err
+	} else if len(x) != ed25519.PublicKeySize {
+		return nil, ErrInvalidKey
+	}
+	return ed25519.PublicKey(x), nil
+}
+
+// okpSigner is a helper that returns the signer for an OKP JSON Web Key.
+// The "d" member is the seed of the private key.
+func (k *Key) okpSigner() (jsonwt.Signer, error) {
+	pub, err := k.okpPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	seed, err := decode(k.D)
+	if err != nil {
+		return nil, err
+	} else if len(seed) != ed25519.SeedSize {
+		return nil, ErrInvalidKey
+	}
+	key := ed25519.NewKeyFromSeed(seed)
+	if !bytes.Equal(key.Public().(ed25519.PublicKey), pub) {
+		return nil, ErrInvalidKey
+	}
+	s, err := signers.NewEdDSA(key)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// okpVerifier is a helper that returns the verifier for an OKP JSON Web Key.
+func (k *Key) okpVerifier() (jsonwt.Verifier, error) {
+	pub, err := k.okpPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	v, err := signers.NewEdDSAVerifier(pub)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
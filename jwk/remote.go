@@ -0,0 +1,282 @@
+/*
+ * jsonwt - JSON Web Tokens
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package jwk
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mdhender/jsonwt"
+)
+
+// DefaultRefreshInterval is how long keys are cached when the server does not send a max-age.
+const DefaultRefreshInterval = time.Hour
+
+// DefaultMinRefreshInterval is the shortest time allowed between fetches.
+const DefaultMinRefreshInterval = time.Minute
+
+// DefaultFetchTimeout is the longest time allowed for fetching the key set.
+const DefaultFetchTimeout = 10 * time.Second
+
+// maxSetSize is the largest JSON Web Key Set that will be read from the server.
+const maxSetSize = 1 << 20
+
+// RemoteOptions configures a RemoteKeySet.
+// The zero value uses http.DefaultClient, the default intervals, and the DefaultFetchTimeout.
+type RemoteOptions struct {
+	// Client is used to fetch the key set.
+	Client *http.Client
+	// RefreshInterval is how long keys are cached if the response has no Cache-Control max-age.
+	RefreshInterval time.Duration
+	// MinRefreshInterval limits how often the key set is fetched,
+	// including fetches caused by tokens with an unknown "kid".
+	MinRefreshInterval time.Duration
+	// Timeout limits how long a fetch may take, including reading the response.
+	Timeout time.Duration
+}
+
+// NewRemoteKeySet returns a key set that fetches its keys from the JSON Web Key Set at the url.
+// Keys are fetched when first needed and cached as directed by the response's Cache-Control header.
+// If opts is nil, the defaults are used.
+func NewRemoteKeySet(url string, opts *RemoteOptions) *RemoteKeySet {
+	ks := &RemoteKeySet{
+		url:        url,
+		client:     http.DefaultClient,
+		refresh:    DefaultRefreshInterval,
+		minRefresh: DefaultMinRefreshInterval,
+		timeout:    DefaultFetchTimeout,
+		keys:       make(map[string]jsonwt.Verifier),
+	}
+	if opts != nil {
+		if opts.Client != nil {
+			ks.client = opts.Client
+		}
+		if opts.RefreshInterval > 0 {
+			ks.refresh = opts.RefreshInterval
+		}
+		if opts.MinRefreshInterval > 0 {
+			ks.minRefresh = opts.MinRefreshInterval
+		}
+		if opts.Timeout > 0 {
+			ks.timeout = opts.Timeout
+		}
+	}
+	return ks
+}
+
+// RemoteKeySet implements the jsonwt.KeySet interface using a JSON Web Key Set fetched from a server.
+// It is safe to use from concurrent goroutines.
+// The lock is never held while fetching, so a slow server only delays the callers that need new keys.
+// Symmetric ("oct") keys in the key set are ignored.
+type RemoteKeySet struct {
+	url        string
+	client     *http.Client
+	refresh    time.Duration
+	minRefresh time.Duration
+	timeout    time.Duration
+
+	mu        sync.Mutex
+	keys      map[string]jsonwt.Verifier
+	expires   time.Time  // keys must be fetched again after this time
+	lastFetch time.Time  // time of the last attempt to fetch the keys
+	inflight  *fetchCall // the fetch in progress, nil if there is none
+}
+
+// fetchCall is a fetch that is shared by every caller that needs it.
+type fetchCall struct {
+	done chan struct{} // closed when the fetch is finished
+	err  error
+}
+
+// Refresh fetches the key set from the server.
+// If a fetch is already in progress, it waits for that fetch instead of starting another.
+// If the fetch fails, the previously fetched keys are kept.
+func (ks *RemoteKeySet) Refresh(ctx context.Context) error {
+	return ks.update(ctx)
+}
+
+// Run refreshes the key set whenever the cached keys expire.
+// It returns when the context is cancelled.
+func (ks *RemoteKeySet) Run(ctx context.Context) {
+	for {
+		ks.mu.Lock()
+		expired := time.Now().After(ks.expires)
+		ks.mu.Unlock()
+		if expired {
+			_ = ks.update(ctx)
+		}
+
+		ks.mu.Lock()
+		wait := time.Until(ks.expires)
+		ks.mu.Unlock()
+		if wait < ks.minRefresh {
+			wait = ks.minRefresh
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// Verifier implements the jsonwt.KeySet interface.
+// It fetches the key set if the cached keys have expired.
+// If the key id is not in the set, it fetches the key set again in case the
+// server has rotated its keys, but no more often than the minimum refresh interval.
+func (ks *RemoteKeySet) Verifier(kid string) (jsonwt.Verifier, error) {
+	ks.mu.Lock()
+	v, ok := ks.keys[kid]
+	expired := time.Now().After(ks.expires) && time.Since(ks.lastFetch) >= ks.minRefresh
+	ks.mu.Unlock()
+	if ok && !expired {
+		return v, nil
+	} else if expired {
+		_ = ks.update(context.Background()) // on failure, use the keys we have
+	}
+
+	ks.mu.Lock()
+	v, ok = ks.keys[kid]
+	// join a fetch in progress since it may have the key we're looking for
+	canFetch := ks.inflight != nil || time.Since(ks.lastFetch) >= ks.minRefresh
+	ks.mu.Unlock()
+	if ok {
+		return v, nil
+	} else if !canFetch {
+		return nil, jsonwt.ErrNotMyKID
+	} else if err := ks.update(context.Background()); err != nil {
+		return nil, err
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if v, ok = ks.keys[kid]; ok {
+		return v, nil
+	}
+	return nil, jsonwt.ErrNotMyKID
+}
+
+// update is a helper that fetches the key set and updates the cache.
+// If a fetch is already in progress, it waits for that fetch instead of starting another.
+// The caller must not hold the lock.
+func (ks *RemoteKeySet) update(ctx context.Context) error {
+	ks.mu.Lock()
+	if c := ks.inflight; c != nil {
+		ks.mu.Unlock()
+		select {
+		case <-c.done:
+			return c.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	c := &fetchCall{done: make(chan struct{})}
+	ks.inflight, ks.lastFetch = c, time.Now()
+	ks.mu.Unlock()
+
+	keys, ttl, err := ks.fetch(ctx)
+
+	ks.mu.Lock()
+	if err == nil {
+		ks.keys, ks.expires = keys, time.Now().Add(ttl)
+	}
+	ks.inflight, c.err = nil, err
+	ks.mu.Unlock()
+	close(c.done)
+
+	return err
+}
+
+// fetch is a helper that fetches the key set from the server.
+// It returns the keys and how long they may be cached.
+func (ks *RemoteKeySet) fetch(ctx context.Context) (map[string]jsonwt.Verifier, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, ks.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Accept", "application/jwk-set+json, application/json")
+	rsp, err := ks.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("jwk: fetch %s: %s", ks.url, rsp.Status)
+	}
+	b, err := io.ReadAll(io.LimitReader(rsp.Body, maxSetSize))
+	if err != nil {
+		return nil, 0, err
+	}
+	set, err := Parse(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	// a published key set must never contain secrets, and anyone who read
+	// the set could use a symmetric key to sign their own tokens
+	keys, err := set.Public().Verifiers()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ttl := ks.refresh
+	if maxAge, ok := parseMaxAge(rsp.Header.Get("Cache-Control")); ok {
+		ttl = maxAge
+	}
+	if ttl < ks.minRefresh {
+		ttl = ks.minRefresh
+	}
+
+	return keys, ttl, nil
+}
+
+// parseMaxAge is a helper function that returns the max-age from a Cache-Control header.
+// The no-cache and no-store directives are treated as a max-age of zero.
+func parseMaxAge(cacheControl string) (time.Duration, bool) {
+	var maxAge time.Duration
+	var found bool
+	for _, directive := range strings.Split(cacheControl, ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		if directive == "no-cache" || directive == "no-store" {
+			return 0, true
+		} else if strings.HasPrefix(directive, "max-age=") {
+			seconds, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(directive, "max-age="), `"`))
+			if err != nil || seconds < 0 {
+				continue
+			}
+			maxAge, found = time.Duration(seconds)*time.Second, true
+		}
+	}
+	return maxAge, found
+}
@@ -0,0 +1,292 @@
+/*
+ * jsonwt - JSON Web Tokens
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package jwk_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mdhender/jsonwt"
+	"github.com/mdhender/jsonwt/jwk"
+	"github.com/mdhender/jsonwt/signers"
+)
+
+// idp is a stand-in for an identity provider that publishes its keys.
+type idp struct {
+	*httptest.Server
+	kr       *jsonwt.Keyring
+	requests int32 // number of requests for the key set
+
+	mu           sync.Mutex
+	cacheControl string        // overrides the handler's Cache-Control header if set
+	delay        time.Duration // delay before responding
+}
+
+// newIDP is a helper that returns an identity provider with a single EdDSA signing key.
+func newIDP(t *testing.T, kid string) *idp {
+	t.Helper()
+	p := &idp{kr: jsonwt.NewKeyring()}
+	p.addKey(t, kid)
+	h := jwk.Handler(p.kr, time.Hour)
+	p.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&p.requests, 1)
+		p.mu.Lock()
+		cacheControl, delay := p.cacheControl, p.delay
+		p.mu.Unlock()
+		if delay != 0 {
+			select {
+			case <-time.After(delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		for k, v := range rec.Header() {
+			w.Header()[k] = v
+		}
+		if cacheControl != "" {
+			w.Header().Set("Cache-Control", cacheControl)
+		}
+		w.WriteHeader(rec.Code)
+		_, _ = w.Write(rec.Body.Bytes())
+	}))
+	t.Cleanup(p.Close)
+	return p
+}
+
+// addKey is a helper that makes a new key the identity provider's signing key.
+func (p *idp) addKey(t *testing.T, kid string) {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := signers.NewEdDSA(key)
+	if err != nil {
+		t.Fatal(err)
+	} else if err = p.kr.SetSigner(kid, s); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (p *idp) set(cacheControl string, delay time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cacheControl, p.delay = cacheControl, delay
+}
+
+func (p *idp) count() int {
+	return int(atomic.LoadInt32(&p.requests))
+}
+
+func TestRemoteKeySetValidatesTokens(t *testing.T) {
+	p := newIDP(t, "k1")
+	tok, err := jsonwt.NewKeyringFactory(p.kr).Token(time.Minute, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := jsonwt.Decode(tok.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := jsonwt.NewKeySetFactory(jwk.NewRemoteKeySet(p.URL, nil))
+	if err = f.Validate(decoded); err != nil {
+		t.Errorf("validate: got %v, want nil", err)
+	}
+}
+
+func TestRemoteKeySetIgnoresSymmetricKeys(t *testing.T) {
+	p := newIDP(t, "k1")
+	hs, err := signers.NewHS256([]byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// publish the secret, as a misconfigured server might
+	set, err := jwk.FromKeyring(p.kr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oct, err := jwk.New("hs", hs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set.Keys = append(set.Keys, oct)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/jwk-set+json")
+		_ = json.NewEncoder(w).Encode(set)
+	}))
+	defer srv.Close()
+
+	kr := jsonwt.NewKeyring()
+	if err = kr.SetSigner("hs", hs); err != nil {
+		t.Fatal(err)
+	}
+	tok, err := jsonwt.NewKeyringFactory(kr).Token(time.Minute, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := jsonwt.Decode(tok.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ks := jwk.NewRemoteKeySet(srv.URL, nil)
+	if _, err = ks.Verifier("k1"); err != nil {
+		t.Errorf("k1: got %v, want nil", err)
+	}
+	if _, err = ks.Verifier("hs"); !errors.Is(err, jsonwt.ErrNotMyKID) {
+		t.Errorf("hs: got %v, want %v", err, jsonwt.ErrNotMyKID)
+	}
+	if err = jsonwt.NewKeySetFactory(ks).Validate(decoded); err == nil {
+		t.Errorf("validate: got nil, want error")
+	}
+}
+
+func TestRemoteKeySetCacheControl(t *testing.T) {
+	for _, tc := range []struct {
+		cacheControl string
+		want         int
+	}{
+		{"", 1}, // the handler's max-age of an hour
+		{"public, max-age=3600", 1},
+		{"no-cache", 3},
+		{"no-store", 3},
+		{"max-age=0", 3},
+	} {
+		t.Run(tc.cacheControl, func(t *testing.T) {
+			p := newIDP(t, "k1")
+			p.set(tc.cacheControl, 0)
+			ks := jwk.NewRemoteKeySet(p.URL, &jwk.RemoteOptions{MinRefreshInterval: time.Nanosecond})
+			for i := 0; i < 3; i++ {
+				if _, err := ks.Verifier("k1"); err != nil {
+					t.Fatal(err)
+				}
+				time.Sleep(time.Millisecond) // let the minimum refresh interval pass
+			}
+			if got := p.count(); got != tc.want {
+				t.Errorf("requests: got %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRemoteKeySetUnknownKID(t *testing.T) {
+	p := newIDP(t, "k1")
+	ks := jwk.NewRemoteKeySet(p.URL, &jwk.RemoteOptions{MinRefreshInterval: 50 * time.Millisecond})
+	if _, err := ks.Verifier("k1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// a key that isn't published is rate limited
+	if _, err := ks.Verifier("k2"); !errors.Is(err, jsonwt.ErrNotMyKID) {
+		t.Errorf("k2: got %v, want %v", err, jsonwt.ErrNotMyKID)
+	} else if got := p.count(); got != 1 {
+		t.Errorf("k2: requests: got %d, want 1", got)
+	}
+
+	// after the interval, an unknown kid fetches the rotated key set
+	p.addKey(t, "k2")
+	time.Sleep(60 * time.Millisecond)
+	if _, err := ks.Verifier("k2"); err != nil {
+		t.Errorf("rotated: got %v, want nil", err)
+	} else if got := p.count(); got != 2 {
+		t.Errorf("rotated: requests: got %d, want 2", got)
+	}
+
+	// unknown kids are not fetched again until the interval passes
+	for i := 0; i < 5; i++ {
+		if _, err := ks.Verifier("k3"); !errors.Is(err, jsonwt.ErrNotMyKID) {
+			t.Errorf("k3: got %v, want %v", err, jsonwt.ErrNotMyKID)
+		}
+	}
+	if got := p.count(); got != 2 {
+		t.Errorf("k3: requests: got %d, want 2", got)
+	}
+}
+
+func TestRemoteKeySetSharesFetch(t *testing.T) {
+	p := newIDP(t, "k1")
+	p.set("", 50*time.Millisecond)
+	ks := jwk.NewRemoteKeySet(p.URL, nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := ks.Verifier("k1"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := p.count(); got != 1 {
+		t.Errorf("requests: got %d, want 1", got)
+	}
+}
+
+func TestRemoteKeySetTimeout(t *testing.T) {
+	p := newIDP(t, "k1")
+	ks := jwk.NewRemoteKeySet(p.URL, &jwk.RemoteOptions{MinRefreshInterval: time.Nanosecond, Timeout: 50 * time.Millisecond})
+	if _, err := ks.Verifier("k1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// a hung server must not block callers that have the key cached
+	p.set("", time.Minute)
+	done := make(chan error, 1)
+	go func() { done <- ks.Refresh(context.Background()) }()
+	time.Sleep(10 * time.Millisecond) // let the refresh start
+	if _, err := ks.Verifier("k1"); err != nil {
+		t.Errorf("cached: got %v, want nil", err)
+	}
+	select {
+	case <-done:
+		t.Errorf("cached: waited for the fetch in progress")
+	default:
+	}
+
+	// the fetch gives up after the timeout and the cached keys are kept
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("refresh: got %v, want %v", err, context.DeadlineExceeded)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("refresh: did not time out")
+	}
+	if _, err := ks.Verifier("k1"); err != nil {
+		t.Errorf("after timeout: got %v, want nil", err)
+	}
+}
@@ -0,0 +1,148 @@
+/*
+ * jsonwt - JSON Web Tokens
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package jwk
+
+import (
+	"crypto/rsa"
+	"math/big"
+
+	"github.com/mdhender/jsonwt"
+	"github.com/mdhender/jsonwt/signers"
+)
+
+// fromRSA is a helper function that returns the JSON Web Key for an RSA public key.
+func fromRSA(pub *rsa.PublicKey) (*Key, error) {
+	if pub == nil || pub.N == nil {
+		return nil, ErrInvalidKey
+	}
+	return &Key{
+		KeyType: "RSA",
+		N:       encode(pub.N.Bytes()),
+		E:       encode(big.NewInt(int64(pub.E)).Bytes()),
+	}, nil
+}
+
+// rsaPublicKey is a helper that returns the public key from an RSA JSON Web Key.
+func (k *Key) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := decodeInt(k.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := decodeInt(k.E)
+	if err != nil {
+		return nil, err
+	} else if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+		return nil, ErrInvalidKey
+	}
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+// rsaPrivateKey is a helper that returns the private key from an RSA JSON Web Key.
+func (k *Key) rsaPrivateKey() (*rsa.PrivateKey, error) {
+	pub, err := k.rsaPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	d, err := decodeInt(k.D)
+	if err != nil {
+		return nil, err
+	}
+	// the primes are optional in RFC 7518, but crypto/rsa needs them
+	p, err := decodeInt(k.P)
+	if err != nil {
+		return nil, err
+	}
+	q, err := decodeInt(k.Q)
+	if err != nil {
+		return nil, err
+	}
+	key := &rsa.PrivateKey{PublicKey: *pub, D: d, Primes: []*big.Int{p, q}}
+	if err = key.Validate(); err != nil {
+		return nil, ErrInvalidKey
+	}
+	key.Precompute()
+	return key, nil
+}
+
+// rsaSigner is a helper that returns the signer for an RSA JSON Web Key.
+func (k *Key) rsaSigner() (jsonwt.Signer, error) {
+	key, err := k.rsaPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	var s jsonwt.Signer
+	switch k.Algorithm {
+	case "":
+		return nil, ErrMissingAlgorithm
+	case "RS256":
+		s, err = signers.NewRS256(key)
+	case "RS384":
+		s, err = signers.NewRS384(key)
+	case "RS512":
+		s, err = signers.NewRS512(key)
+	case "PS256":
+		s, err = signers.NewPS256(key)
+	case "PS384":
+		s, err = signers.NewPS384(key)
+	case "PS512":
+		s, err = signers.NewPS512(key)
+	default:
+		return nil, ErrUnsupportedAlgorithm
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// rsaVerifier is a helper that returns the verifier for an RSA JSON Web Key.
+func (k *Key) rsaVerifier() (jsonwt.Verifier, error) {
+	pub, err := k.rsaPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	var v jsonwt.Verifier
+	switch k.Algorithm {
+	case "":
+		return nil, ErrMissingAlgorithm
+	case "RS256":
+		v, err = signers.NewRS256Verifier(pub)
+	case "RS384":
+		v, err = signers.NewRS384Verifier(pub)
+	case "RS512":
+		v, err = signers.NewRS512Verifier(pub)
+	case "PS256":
+		v, err = signers.NewPS256Verifier(pub)
+	case "PS384":
+		v, err = signers.NewPS384Verifier(pub)
+	case "PS512":
+		v, err = signers.NewPS512Verifier(pub)
+	default:
+		return nil, ErrUnsupportedAlgorithm
+	}
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
@@ -0,0 +1,129 @@
+/*
+ * jsonwt - JSON Web Tokens
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package jwk
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/mdhender/jsonwt"
+)
+
+// Set is a JSON Web Key Set.
+type Set struct {
+	Keys []*Key `json:"keys"`
+}
+
+// FromKeyring returns a Set containing the public keys on the keyring.
+// HMAC secrets and keys that can't be represented as a JSON Web Key are not included.
+func FromKeyring(kr *jsonwt.Keyring) (*Set, error) {
+	s := &Set{Keys: []*Key{}}
+	for _, kid := range kr.Keys() {
+		v, err := kr.Verifier(kid)
+		if errors.Is(err, jsonwt.ErrNotMyKID) {
+			continue // retired since we fetched the list of keys
+		} else if err != nil {
+			return nil, err
+		}
+		k, err := New(kid, v)
+		if errors.Is(err, ErrUnsupportedKeyType) {
+			continue
+		} else if err != nil {
+			return nil, err
+		} else if k.IsPrivate() {
+			continue // never publish secrets
+		}
+		s.Keys = append(s.Keys, k)
+	}
+	return s, nil
+}
+
+// Parse returns the Set from the JSON document.
+func Parse(data []byte) (*Set, error) {
+	var s Set
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Key returns the key with the given id or nil if there is no such key.
+func (s *Set) Key(kid string) *Key {
+	for _, k := range s.Keys {
+		if k.KeyID == kid {
+			return k
+		}
+	}
+	return nil
+}
+
+// Public returns a copy of the set with all private and secret members removed.
+func (s *Set) Public() *Set {
+	ps := &Set{Keys: make([]*Key, 0, len(s.Keys))}
+	for _, k := range s.Keys {
+		if k.KeyType == "oct" {
+			continue
+		}
+		ps.Keys = append(ps.Keys, k.Public())
+	}
+	return ps
+}
+
+// Verifiers returns the verifiers for the keys in the set, indexed by key id.
+// As recommended by RFC 7517, keys that are not used for signatures,
+// that have an unsupported key type or algorithm,
+// or whose algorithm can't be determined (for example, an RSA key without "alg") are ignored.
+// It returns an error if a key is missing its id or can't be decoded.
+func (s *Set) Verifiers() (map[string]jsonwt.Verifier, error) {
+	verifiers := make(map[string]jsonwt.Verifier)
+	for _, k := range s.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		v, err := k.Verifier()
+		if errors.Is(err, ErrUnsupportedKeyType) || errors.Is(err, ErrUnsupportedAlgorithm) || errors.Is(err, ErrMissingAlgorithm) {
+			continue
+		} else if err != nil {
+			return nil, err
+		} else if k.KeyID == "" {
+			return nil, ErrMissingKID
+		} else if _, ok := verifiers[k.KeyID]; ok {
+			return nil, jsonwt.ErrDuplicateKID
+		}
+		verifiers[k.KeyID] = v
+	}
+	return verifiers, nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// It always emits the "keys" member, even when the set is empty.
+func (s *Set) MarshalJSON() ([]byte, error) {
+	keys := s.Keys
+	if keys == nil {
+		keys = []*Key{}
+	}
+	return json.Marshal(struct {
+		Keys []*Key `json:"keys"`
+	}{Keys: keys})
+}
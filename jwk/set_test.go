@@ -0,0 +1,81 @@
+/*
+ * jsonwt - JSON Web Tokens
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package jwk_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mdhender/jsonwt"
+	"github.com/mdhender/jsonwt/jwk"
+)
+
+func TestSetVerifiersSkipsUnusableKeys(t *testing.T) {
+	ec := loadKey(t, "rfc7520-ec.json").Public()
+	rsa := loadKey(t, "rfc7520-rsa.json").Public() // no "alg" member
+	enc := loadKey(t, "rfc7520-rsa.json").Public()
+	enc.KeyID, enc.Use, enc.Algorithm = "enc", "enc", "RSA-OAEP"
+	unknownAlg := loadKey(t, "rfc7520-rsa.json").Public()
+	unknownAlg.KeyID, unknownAlg.Algorithm = "unknown-alg", "RSA-OAEP"
+	unknownKty := &jwk.Key{KeyType: "EC", KeyID: "unknown-kty", Curve: "secp256k1", X: "AA", Y: "AA"}
+
+	s := &jwk.Set{Keys: []*jwk.Key{rsa, enc, unknownAlg, unknownKty, ec}}
+	verifiers, err := s.Verifiers()
+	if err != nil {
+		t.Fatalf("verifiers: got %v, want nil", err)
+	}
+	if len(verifiers) != 1 {
+		t.Errorf("verifiers: got %d, want 1", len(verifiers))
+	}
+	if v, ok := verifiers[ec.KeyID]; !ok {
+		t.Errorf("verifiers: missing %q", ec.KeyID)
+	} else if v.Algorithm() != "ES512" {
+		t.Errorf("verifiers: alg: got %q, want %q", v.Algorithm(), "ES512")
+	}
+}
+
+func TestSetVerifiersErrors(t *testing.T) {
+	noKID := loadKey(t, "rfc7520-ec.json").Public()
+	noKID.KeyID = ""
+	invalid := loadKey(t, "rfc7520-ec.json").Public()
+	invalid.KeyID, invalid.X = "invalid", "AA"
+	dup := loadKey(t, "rfc7520-ec.json").Public()
+
+	for _, tc := range []struct {
+		name string
+		keys []*jwk.Key
+		want error
+	}{
+		{"missing kid", []*jwk.Key{noKID}, jwk.ErrMissingKID},
+		{"invalid key", []*jwk.Key{invalid}, jwk.ErrInvalidKey},
+		{"duplicate kid", []*jwk.Key{dup, dup}, jsonwt.ErrDuplicateKID},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &jwk.Set{Keys: tc.keys}
+			if _, err := s.Verifiers(); !errors.Is(err, tc.want) {
+				t.Errorf("verifiers: got %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
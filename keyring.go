@@ -0,0 +1,132 @@
+/*
+ * jsonwt - JSON Web Tokens
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package jsonwt
+
+import (
+	"sort"
+	"sync"
+)
+
+// KeySet is a collection of Verifiers indexed by key id.
+type KeySet interface {
+	// Verifier returns the Verifier for the key id.
+	// It returns ErrNotMyKID if the key id is not in the set.
+	Verifier(kid string) (Verifier, error)
+}
+
+// NewKeyring returns an empty Keyring.
+// Use SetSigner to add the active signing key and Add to add keys that can only validate tokens.
+func NewKeyring() *Keyring {
+	return &Keyring{keys: make(map[string]Verifier)}
+}
+
+// Keyring holds the keys used to validate tokens along with a single active signing key.
+// It allows keys to be rotated without invalidating tokens signed with the previous key.
+// It is safe to use from concurrent goroutines.
+type Keyring struct {
+	mu   sync.RWMutex
+	kid  string // id of the active signing key
+	s    Signer
+	keys map[string]Verifier
+}
+
+// Add adds a key that will be used to validate tokens with the given key id.
+// It returns ErrBadKey if the key id is empty or the verifier is nil.
+// It returns ErrDuplicateKID if the key id is already on the keyring.
+func (k *Keyring) Add(kid string, v Verifier) error {
+	if kid == "" || v == nil {
+		return ErrBadKey
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, ok := k.keys[kid]; ok {
+		return ErrDuplicateKID
+	}
+	k.keys[kid] = v
+	return nil
+}
+
+// Keys returns the ids of all the keys on the keyring, sorted.
+func (k *Keyring) Keys() []string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	var kids []string
+	for kid := range k.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+	return kids
+}
+
+// Retire removes the key from the keyring.
+// Tokens signed with the key will no longer validate.
+// If it is the active signing key, the keyring will not sign tokens until SetSigner is called.
+func (k *Keyring) Retire(kid string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	delete(k.keys, kid)
+	if k.kid == kid {
+		k.kid, k.s = "", nil
+	}
+}
+
+// SetSigner makes the signer the active signing key.
+// It is also added to the keyring (replacing any key with the same id) so that the tokens it signs can be validated.
+// If the signer does not implement the Verifier interface, tokens are validated the same way as NewFactory does,
+// by signing the message again and comparing the signatures in constant time.
+// The previous signing key stays on the keyring until it is retired.
+// It returns ErrBadKey if the key id is empty or the signer is nil.
+func (k *Keyring) SetSigner(kid string, s Signer) error {
+	if kid == "" || s == nil {
+		return ErrBadKey
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if v, ok := s.(Verifier); ok {
+		k.keys[kid] = v
+	} else {
+		k.keys[kid] = signerVerifier{s: s}
+	}
+	k.kid, k.s = kid, s
+	return nil
+}
+
+// Signer returns the id and Signer of the active signing key.
+// The Signer is nil if there is no active signing key.
+func (k *Keyring) Signer() (string, Signer) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.kid, k.s
+}
+
+// Verifier implements the KeySet interface.
+func (k *Keyring) Verifier(kid string) (Verifier, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	v, ok := k.keys[kid]
+	if !ok {
+		return nil, ErrNotMyKID
+	}
+	return v, nil
+}
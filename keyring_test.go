@@ -0,0 +1,57 @@
+/*******************************************************************************
+jsonwt - JSON Web Tokens
+Copyright (c) 2022 Michael D Henderson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+******************************************************************************/
+
+package jsonwt_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mdhender/jsonwt"
+)
+
+// signOnly hides the Verify method of the signer.
+type signOnly struct {
+	jsonwt.Signer
+}
+
+func TestKeyringSignerWithoutVerifier(t *testing.T) {
+	kr := jsonwt.NewKeyring()
+	if err := kr.SetSigner("k1", signOnly{newHS256(t)}); err != nil {
+		t.Fatal(err)
+	}
+	f := jsonwt.NewKeyringFactory(kr)
+	tok, err := f.Token(time.Minute, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = f.Validate(decodeWithSignature(t, tok, tok.Signature())); err != nil {
+		t.Errorf("validate: got %v, want nil", err)
+	}
+
+	kr.Retire("k1")
+	if err = f.Validate(decodeWithSignature(t, tok, tok.Signature())); !errors.Is(err, jsonwt.ErrNotMyKID) {
+		t.Errorf("retired: got %v, want %v", err, jsonwt.ErrNotMyKID)
+	}
+}
@@ -0,0 +1,112 @@
+/*
+ * jsonwt - JSON Web Tokens
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package jsonwt
+
+import (
+	"errors"
+	"net/http"
+)
+
+// MiddlewareOptions configures the authentication middleware.
+// The zero value requires a valid Token on every request.
+type MiddlewareOptions struct {
+	// Optional passes requests that don't have a Token (including requests that
+	// use a different authentication scheme) to the next handler.
+	// Requests with a Token that is not valid are still rejected.
+	Optional bool
+	// Extract configures where the Token may be found in the request.
+	Extract *ExtractOptions
+	// Policies are the validation policies that the Token must satisfy.
+	Policies []*ValidationPolicy
+	// ErrorHandler is called when a request is rejected.
+	// The error is the reason the request was rejected.
+	// If it is nil, DefaultErrorHandler is used.
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// Middleware returns a middleware that authenticates requests.
+// It fetches the Token from the request (see ExtractToken), validates the signature and timestamps,
+// and stores the Token in the request's context for the next handler
+// (use FromContext to retrieve it). If opts is nil, the defaults are used.
+func Middleware(f *Factory, opts *MiddlewareOptions) func(http.Handler) http.Handler {
+	if opts == nil {
+		opts = &MiddlewareOptions{}
+	}
+	onError := opts.ErrorHandler
+	if onError == nil {
+		onError = DefaultErrorHandler
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t, err := ExtractToken(r, opts.Extract)
+			if opts.Optional && (errors.Is(err, ErrMissingToken) || errors.Is(err, ErrNotBearer)) {
+				next.ServeHTTP(w, r)
+				return
+			} else if err != nil {
+				onError(w, r, err)
+				return
+			}
+			if err = f.Validate(t, opts.Policies...); err != nil {
+				onError(w, r, err)
+				return
+			} else if err = t.Validate(); err != nil {
+				onError(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(t.NewContext(r.Context())))
+		})
+	}
+}
+
+// DefaultErrorHandler responds to a request that was rejected by the middleware
+// with a Bearer challenge in the WWW-Authenticate header (RFC 6750 section 3.1).
+// Requests without a Token get 401 Unauthorized with no error code.
+// Requests with more than one Token get 400 Bad Request and "invalid_request".
+// All other requests get 401 Unauthorized and "invalid_token".
+// The error description is a fixed message for each kind of error so that details
+// like key ids and server times are not sent to the client. Use a custom ErrorHandler
+// to log the reason the request was rejected.
+func DefaultErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrMissingToken), errors.Is(err, ErrNotBearer):
+		WriteChallenge(w, http.StatusUnauthorized, Challenge{})
+	case errors.Is(err, ErrBadRequest):
+		WriteChallenge(w, http.StatusBadRequest, Challenge{Error: ErrorCodeInvalidRequest, ErrorDescription: "more than one token in the request"})
+	default:
+		WriteChallenge(w, http.StatusUnauthorized, Challenge{Error: ErrorCodeInvalidToken, ErrorDescription: tokenErrorDescription(err)})
+	}
+}
+
+// tokenErrorDescription is a helper function that returns a description of the error that is safe to send to clients.
+func tokenErrorDescription(err error) string {
+	switch {
+	case errors.Is(err, ErrExpired), errors.Is(err, ErrTooOld):
+		return "the token has expired"
+	case errors.Is(err, ErrNotYetValid):
+		return "the token is not valid yet"
+	case errors.Is(err, ErrMalformedToken):
+		return "the token is malformed"
+	}
+	return "the token is not valid"
+}
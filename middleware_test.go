@@ -0,0 +1,150 @@
+/*******************************************************************************
+jsonwt - JSON Web Tokens
+Copyright (c) 2022 Michael D Henderson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+******************************************************************************/
+
+package jsonwt_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mdhender/jsonwt"
+	"github.com/mdhender/jsonwt/signers"
+)
+
+// serve is a helper that sends the request through the handler and returns the response.
+// The handler records the Token that it finds in the request's context.
+func serve(mw func(http.Handler) http.Handler, r *http.Request) (*httptest.ResponseRecorder, *jsonwt.Token, bool) {
+	var t *jsonwt.Token
+	var called bool
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		t, _ = jsonwt.FromContext(r.Context())
+	})
+	w := httptest.NewRecorder()
+	mw(next).ServeHTTP(w, r)
+	return w, t, called
+}
+
+// bearerRequest is a helper that returns a request with the token in the Authorization header.
+func bearerRequest(token string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	return r
+}
+
+func TestMiddleware(t *testing.T) {
+	f := jsonwt.NewFactory("k", newHS256(t))
+	valid, err := f.Token(time.Minute, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a token that expired an hour ago
+	expiredFactory := jsonwt.NewFactory("k", newHS256(t))
+	expiredFactory.SetClock(jsonwt.NewFakeClock(time.Now().Add(-time.Hour)))
+	expired, err := expiredFactory.Token(time.Minute, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a token signed with a key that the factory doesn't know
+	other, err := signers.NewHS256([]byte("abcdef0123456789abcdef0123456789"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	unknownKID, err := jsonwt.NewFactory("other", other).Token(time.Minute, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	basic := httptest.NewRequest(http.MethodGet, "/", nil)
+	basic.SetBasicAuth("bilbo", "baggins")
+
+	for _, tc := range []struct {
+		name      string
+		opts      *jsonwt.MiddlewareOptions
+		r         *http.Request
+		code      int
+		challenge string
+	}{
+		{"valid", nil, bearerRequest(valid.String()), http.StatusOK, ""},
+		{"missing", nil, bearerRequest(""), http.StatusUnauthorized, "Bearer"},
+		{"other scheme", nil, basic, http.StatusUnauthorized, "Bearer"},
+		{"expired", nil, bearerRequest(expired.String()), http.StatusUnauthorized, `Bearer error="invalid_token", error_description="the token has expired"`},
+		{"unknown kid", nil, bearerRequest(unknownKID.String()), http.StatusUnauthorized, `Bearer error="invalid_token", error_description="the token is not valid"`},
+		{"malformed", nil, bearerRequest("not.a.token"), http.StatusUnauthorized, `Bearer error="invalid_token", error_description="the token is malformed"`},
+		{"policy", &jsonwt.MiddlewareOptions{Policies: []*jsonwt.ValidationPolicy{{Issuers: []string{"shire"}}}}, bearerRequest(valid.String()), http.StatusUnauthorized, `Bearer error="invalid_token", error_description="the token is not valid"`},
+		{"optional valid", &jsonwt.MiddlewareOptions{Optional: true}, bearerRequest(valid.String()), http.StatusOK, ""},
+		{"optional missing", &jsonwt.MiddlewareOptions{Optional: true}, bearerRequest(""), http.StatusOK, ""},
+		{"optional other scheme", &jsonwt.MiddlewareOptions{Optional: true}, basic, http.StatusOK, ""},
+		{"optional expired", &jsonwt.MiddlewareOptions{Optional: true}, bearerRequest(expired.String()), http.StatusUnauthorized, `Bearer error="invalid_token", error_description="the token has expired"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w, tok, called := serve(jsonwt.Middleware(f, tc.opts), tc.r)
+			if w.Code != tc.code {
+				t.Errorf("code: got %d, want %d", w.Code, tc.code)
+			}
+			if got := w.Header().Get("WWW-Authenticate"); got != tc.challenge {
+				t.Errorf("challenge: got %q, want %q", got, tc.challenge)
+			}
+			if called != (tc.code == http.StatusOK) {
+				t.Errorf("next: got %v, want %v", called, tc.code == http.StatusOK)
+			}
+			// only authenticated requests have a Token in the context
+			if hasToken := called && tc.r.Header.Get("Authorization") == "Bearer "+valid.String(); hasToken && (tok == nil || tok.String() != valid.String()) {
+				t.Errorf("context: got %v, want the token", tok)
+			} else if !hasToken && tok != nil {
+				t.Errorf("context: got %v, want nil", tok)
+			}
+		})
+	}
+}
+
+func TestMiddlewareErrorHandler(t *testing.T) {
+	f := jsonwt.NewFactory("k", newHS256(t))
+	f.SetClock(jsonwt.NewFakeClock(time.Now().Add(-time.Hour)))
+	expired, err := f.Token(time.Minute, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.SetClock(nil)
+
+	// the error handler is given the structured reason
+	var reason error
+	opts := &jsonwt.MiddlewareOptions{ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+		reason = err
+		w.WriteHeader(http.StatusTeapot)
+	}}
+	w, _, _ := serve(jsonwt.Middleware(f, opts), bearerRequest(expired.String()))
+	var te *jsonwt.TimeError
+	if w.Code != http.StatusTeapot {
+		t.Errorf("code: got %d, want %d", w.Code, http.StatusTeapot)
+	} else if !errors.As(reason, &te) || !errors.Is(reason, jsonwt.ErrExpired) {
+		t.Errorf("reason: got %v, want *TimeError wrapping %v", reason, jsonwt.ErrExpired)
+	}
+}
@@ -0,0 +1,80 @@
+/*******************************************************************************
+jsonwt - JSON Web Tokens
+Copyright (c) 2022 Michael D Henderson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+******************************************************************************/
+
+package jsonwt_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mdhender/jsonwt"
+)
+
+func TestNumericDate(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		d    jsonwt.NumericDate
+		want time.Time
+	}{
+		{"zero", 0, time.Time{}},
+		{"integer", 1654084800, t0},
+		{"fraction", 1654084800.25, t0.Add(250 * time.Millisecond)},
+		{"before the epoch", -1.5, time.Unix(-2, 5e8).UTC()},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.d.Time(); !got.Equal(tc.want) {
+				t.Errorf("time: got %v, want %v", got, tc.want)
+			}
+		})
+	}
+	if got := jsonwt.NewNumericDate(t0.Add(999 * time.Millisecond)); got != 1654084800 {
+		t.Errorf("new: got %v, want %v", got, jsonwt.NumericDate(1654084800))
+	}
+}
+
+func TestTokenValidateFractionalTimestamps(t *testing.T) {
+	const payload = `{"iat":1654084800.25,"nbf":1654084860.75,"exp":1654085400.5}`
+	for _, tc := range []struct {
+		name string
+		now  time.Duration // offset from t0
+		err  error
+	}{
+		{"before iat", 0, jsonwt.ErrNotYetValid},
+		{"before nbf", 60500 * time.Millisecond, jsonwt.ErrNotYetValid},
+		{"at nbf", 60750 * time.Millisecond, nil},
+		{"before exp", 600250 * time.Millisecond, nil},
+		{"at exp", 600500 * time.Millisecond, jsonwt.ErrExpired},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			clock := jsonwt.NewFakeClock(t0.Add(time.Minute + 750*time.Millisecond))
+			f := jsonwt.NewFactory("k", newHS256(t))
+			f.SetClock(clock)
+			tok := signedToken(t, f, payload)
+			clock.Set(t0.Add(tc.now))
+			if err := tok.Validate(); !errors.Is(err, tc.err) {
+				t.Errorf("got %v, want %v", err, tc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+/*
+ * jsonwt - JSON Web Tokens
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package jsonwt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DefaultMaxLength is the longest token accepted by the DefaultParser.
+const DefaultMaxLength = 8 * 1024
+
+// DefaultParser is used by Decode and by the functions that fetch tokens from requests.
+var DefaultParser = &Parser{
+	MaxLength:              DefaultMaxLength,
+	RejectDuplicateMembers: true,
+}
+
+// Parser decodes tokens.
+// The zero value decodes tokens of any size and accepts any header.
+// All parsers reject tokens with a "crit" header parameter that they don't understand (RFC 7515 section 4.1.11)
+// and tokens with a "ver" header parameter other than 1.
+type Parser struct {
+	// MaxLength is the longest token accepted.
+	// If it is zero, there is no limit.
+	MaxLength int
+	// RejectDuplicateMembers rejects tokens when a JSON object in the header
+	// or payload has more than one member with the same name.
+	RejectDuplicateMembers bool
+	// DisallowUnknownHeaders rejects tokens with header parameters other than
+	// "ver", "alg", "typ", "kid", "cty", "crit", and the extensions listed in Critical.
+	DisallowUnknownHeaders bool
+	// TokenTypes is the list of accepted values for the header's "typ".
+	// If it is empty, any value (including none) is accepted.
+	TokenTypes []string
+	// Critical is the list of extension header parameters that the application understands.
+	// A token is rejected if its "crit" names a parameter that is not in this list.
+	Critical []string
+}
+
+// Decode expects the data to look like header.payload.signature if it is a valid Token.
+// It does not check the signature; use Factory.Validate for that.
+func (p *Parser) Decode(data string) (*Token, error) {
+	if p.MaxLength != 0 && len(data) > p.MaxLength {
+		return nil, ErrTokenTooLong
+	}
+
+	sections := strings.Split(data, ".")
+	if len(sections) != 3 || len(sections[0]) == 0 || len(sections[1]) == 0 || len(sections[2]) == 0 {
+		return nil, ErrBadToken
+	}
+
+	var t Token
+	t.h.b64 = sections[0]
+	t.p.b64 = sections[1]
+	t.s = sections[2]
+
+	// the header is base64 encoded JSON
+	rawHeader, err := decode(t.h.b64)
+	if err != nil {
+		return nil, err
+	} else if err = p.checkJSON(rawHeader); err != nil {
+		return nil, err
+	} else if err = p.unmarshalHeader(rawHeader, &t); err != nil {
+		return nil, err
+	} else if err = p.checkHeader(rawHeader, &t); err != nil {
+		return nil, err
+	}
+
+	// the payload is base64 encoded JSON
+	rawPayload, err := decode(t.p.b64)
+	if err != nil {
+		return nil, err
+	} else if err = p.checkJSON(rawPayload); err != nil {
+		return nil, err
+	} else if err = t.unmarshalPayload(rawPayload); err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// checkHeader is a helper that checks the "ver", "typ", and "crit" header parameters.
+func (p *Parser) checkHeader(rawHeader []byte, t *Token) error {
+	if t.h.Version != 0 && t.h.Version != 1 {
+		return ErrBadVersion
+	}
+
+	if len(p.TokenTypes) != 0 && !contains(p.TokenTypes, t.h.TokenType) {
+		return ErrBadTokenType
+	}
+
+	var params map[string]json.RawMessage
+	if err := json.Unmarshal(rawHeader, &params); err != nil {
+		return err
+	} else if _, ok := params["crit"]; !ok {
+		return nil
+	} else if len(t.h.Critical) == 0 {
+		return ErrUnsupportedCritical // an empty list is not allowed
+	}
+	for _, name := range t.h.Critical {
+		if jwsHeaderParameters[name] {
+			return ErrUnsupportedCritical // registered parameters must not be listed
+		} else if !contains(p.Critical, name) {
+			return ErrUnsupportedCritical
+		} else if _, ok := params[name]; !ok {
+			return ErrUnsupportedCritical
+		}
+	}
+
+	return nil
+}
+
+// checkJSON is a helper that rejects JSON with duplicate members or trailing data.
+func (p *Parser) checkJSON(data []byte) error {
+	if !p.RejectDuplicateMembers {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := checkDuplicateMembers(dec); err != nil {
+		return err
+	} else if _, err = dec.Token(); err != io.EOF {
+		return ErrBadToken // trailing data
+	}
+	return nil
+}
+
+// unmarshalHeader is a helper that sets the Token's header from its JSON representation.
+func (p *Parser) unmarshalHeader(rawHeader []byte, t *Token) error {
+	if err := json.Unmarshal(rawHeader, &t.h); err != nil || !p.DisallowUnknownHeaders {
+		return err
+	}
+	var params map[string]json.RawMessage
+	if err := json.Unmarshal(rawHeader, &params); err != nil {
+		return err
+	}
+	for name := range params {
+		if !tokenHeaderParameters[name] && !contains(p.Critical, name) {
+			return fmt.Errorf("%w: unknown header parameter %q", ErrBadToken, name)
+		}
+	}
+	return nil
+}
+
+// checkDuplicateMembers is a helper function that reads the next JSON value from the decoder.
+// It returns ErrDuplicateMember if an object in the value has more than one member with the same name.
+// Names are compared without regard to case since encoding/json matches them to struct fields that way;
+// otherwise {"kid":"a","KID":"b"} would pass the check and set the key id to "b".
+func checkDuplicateMembers(dec *json.Decoder) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case json.Delim('{'):
+		names := make(map[string]bool)
+		for dec.More() {
+			tok, err = dec.Token()
+			if err != nil {
+				return err
+			}
+			name, ok := tok.(string)
+			if !ok {
+				return ErrBadToken
+			}
+			name = foldName(name)
+			if names[name] {
+				return ErrDuplicateMember
+			}
+			names[name] = true
+			if err = checkDuplicateMembers(dec); err != nil {
+				return err
+			}
+		}
+		_, err = dec.Token() // consume the closing delimiter
+	case json.Delim('['):
+		for dec.More() {
+			if err = checkDuplicateMembers(dec); err != nil {
+				return err
+			}
+		}
+		_, err = dec.Token() // consume the closing delimiter
+	}
+	return err
+}
+
+// foldName is a helper function that folds the case of a member name the way
+// encoding/json does when it matches names to struct fields (so "ſub" is "sub").
+func foldName(name string) string {
+	return strings.ToLower(strings.ToUpper(name))
+}
+
+// tokenHeaderParameters are the header parameters understood by the Token.
+var tokenHeaderParameters = map[string]bool{
+	"ver":  true,
+	"alg":  true,
+	"typ":  true,
+	"kid":  true,
+	"cty":  true,
+	"crit": true,
+}
+
+// jwsHeaderParameters are the header parameters defined by RFC 7515 and RFC 7516.
+// They must not be listed in "crit".
+var jwsHeaderParameters = map[string]bool{
+	"alg":      true,
+	"jku":      true,
+	"jwk":      true,
+	"kid":      true,
+	"x5u":      true,
+	"x5c":      true,
+	"x5t":      true,
+	"x5t#S256": true,
+	"typ":      true,
+	"cty":      true,
+	"crit":     true,
+	"enc":      true,
+	"zip":      true,
+}
@@ -0,0 +1,122 @@
+/*******************************************************************************
+jsonwt - JSON Web Tokens
+Copyright (c) 2022 Michael D Henderson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+******************************************************************************/
+
+package jsonwt_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mdhender/jsonwt"
+)
+
+// rawToken is a helper function that returns a token with the given header and payload JSON.
+// The signature is not valid since the parser doesn't check it.
+func rawToken(header, payload string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(header)) + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".c2ln"
+}
+
+const testHeader = `{"alg":"HS256","typ":"JWT","kid":"k"}`
+const testPayload = `{"iat":1654084800,"exp":1654085400}`
+
+func TestParserMaxLength(t *testing.T) {
+	data := rawToken(testHeader, `{"exp":1654085400,"name":"`+strings.Repeat("x", jsonwt.DefaultMaxLength)+`"}`)
+	if _, err := jsonwt.Decode(data); !errors.Is(err, jsonwt.ErrTokenTooLong) {
+		t.Errorf("default: got %v, want %v", err, jsonwt.ErrTokenTooLong)
+	}
+	if _, err := (&jsonwt.Parser{}).Decode(data); err != nil {
+		t.Errorf("no limit: got %v, want nil", err)
+	}
+	p := &jsonwt.Parser{MaxLength: len(data) - 1}
+	if _, err := p.Decode(data); !errors.Is(err, jsonwt.ErrTokenTooLong) {
+		t.Errorf("limit: got %v, want %v", err, jsonwt.ErrTokenTooLong)
+	}
+	p.MaxLength = len(data)
+	if _, err := p.Decode(data); err != nil {
+		t.Errorf("at limit: got %v, want nil", err)
+	}
+}
+
+func TestParserRejectsDuplicateMembers(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		header, payload string
+		err             error
+	}{
+		{"header", `{"alg":"HS256","alg":"none","typ":"JWT","kid":"k"}`, testPayload, jsonwt.ErrDuplicateMember},
+		{"payload", testHeader, `{"exp":1654085400,"sub":"bilbo","sub":"admin"}`, jsonwt.ErrDuplicateMember},
+		{"header case", `{"alg":"HS256","typ":"JWT","kid":"k","KID":"other"}`, testPayload, jsonwt.ErrDuplicateMember},
+		{"payload case", testHeader, `{"exp":1654085400,"sub":"bilbo","Sub":"admin"}`, jsonwt.ErrDuplicateMember},
+		{"payload case folding", testHeader, `{"exp":1654085400,"sub":"bilbo","ſub":"admin"}`, jsonwt.ErrDuplicateMember},
+		{"nested", testHeader, `{"exp":1654085400,"x":[{"a":1,"a":2}]}`, jsonwt.ErrDuplicateMember},
+		{"trailing data", testHeader, testPayload + `{}`, jsonwt.ErrBadToken},
+		{"same name in different objects", testHeader, `{"exp":1654085400,"x":{"exp":1},"y":[{"exp":2}]}`, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data := rawToken(tc.header, tc.payload)
+			if _, err := jsonwt.Decode(data); !errors.Is(err, tc.err) {
+				t.Errorf("got %v, want %v", err, tc.err)
+			}
+		})
+	}
+	// the zero value Parser does not check for duplicates
+	if _, err := (&jsonwt.Parser{}).Decode(rawToken(testHeader, `{"exp":1654085400,"sub":"a","sub":"b"}`)); err != nil {
+		t.Errorf("zero value: got %v, want nil", err)
+	}
+}
+
+func TestParserHeader(t *testing.T) {
+	critical := &jsonwt.Parser{Critical: []string{"ext"}}
+	strict := &jsonwt.Parser{DisallowUnknownHeaders: true, Critical: []string{"ext"}}
+	typed := &jsonwt.Parser{TokenTypes: []string{"JWT", "at+jwt"}}
+	for _, tc := range []struct {
+		name   string
+		p      *jsonwt.Parser
+		header string
+		err    error
+	}{
+		{"crit not understood", jsonwt.DefaultParser, `{"alg":"HS256","kid":"k","crit":["ext"],"ext":1}`, jsonwt.ErrUnsupportedCritical},
+		{"crit understood", critical, `{"alg":"HS256","kid":"k","crit":["ext"],"ext":1}`, nil},
+		{"crit missing parameter", critical, `{"alg":"HS256","kid":"k","crit":["ext"]}`, jsonwt.ErrUnsupportedCritical},
+		{"crit empty", critical, `{"alg":"HS256","kid":"k","crit":[]}`, jsonwt.ErrUnsupportedCritical},
+		{"crit registered parameter", &jsonwt.Parser{Critical: []string{"alg"}}, `{"alg":"HS256","kid":"k","crit":["alg"]}`, jsonwt.ErrUnsupportedCritical},
+		{"strict known", strict, testHeader, nil},
+		{"strict unknown", strict, `{"alg":"HS256","kid":"k","jku":"https://example.com/"}`, jsonwt.ErrBadToken},
+		{"strict crit understood", strict, `{"alg":"HS256","kid":"k","crit":["ext"],"ext":1}`, nil},
+		{"strict extension without crit", strict, `{"alg":"HS256","kid":"k","ext":1}`, nil},
+		{"typ accepted", typed, `{"alg":"HS256","typ":"at+jwt","kid":"k"}`, nil},
+		{"typ rejected", typed, `{"alg":"HS256","typ":"JWE","kid":"k"}`, jsonwt.ErrBadTokenType},
+		{"typ missing", typed, `{"alg":"HS256","kid":"k"}`, jsonwt.ErrBadTokenType},
+		{"ver 1", jsonwt.DefaultParser, `{"ver":1,"alg":"HS256","kid":"k"}`, nil},
+		{"ver 2", jsonwt.DefaultParser, `{"ver":2,"alg":"HS256","kid":"k"}`, jsonwt.ErrBadVersion},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := tc.p.Decode(rawToken(tc.header, testPayload)); !errors.Is(err, tc.err) {
+				t.Errorf("got %v, want %v", err, tc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+/*
+ * jsonwt - JSON Web Tokens
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package jsonwt
+
+import (
+	"regexp"
+	"time"
+)
+
+// ValidationPolicy describes the registered claims that a Token must have to be accepted.
+// The zero value accepts every Token.
+type ValidationPolicy struct {
+	// Issuers is the list of accepted issuers.
+	// If it is not empty, the Token's "iss" must be in the list.
+	Issuers []string
+	// Audience is the name this service uses to identify itself.
+	// If it is set, it must be one of the values in the Token's "aud".
+	Audience string
+	// Subject is an optional pattern that the Token's "sub" must match.
+	// The pattern must match the whole subject, as if it were anchored with ^ and $,
+	// so "admin" does not accept "notadmin".
+	Subject *regexp.Regexp
+	// MaxAge is the longest time allowed since the Token was issued.
+	// If it is not zero, the Token must have an "iat" that is no older than MaxAge.
+	MaxAge time.Duration
+}
+
+// Check returns nil only if the Token satisfies the policy.
+// Otherwise, it returns ErrInvalidIssuer, ErrInvalidAudience, ErrInvalidSubject,
+// ErrMissingIssuedAt, or a *TimeError wrapping ErrTooOld.
+// It does not check the signature; use Factory.Validate for that.
+func (p *ValidationPolicy) Check(t *Token) error {
+	if t == nil {
+		return ErrBadToken
+	} else if p == nil {
+		return nil
+	}
+
+	if len(p.Issuers) != 0 && !contains(p.Issuers, t.p.Issuer) {
+		return ErrInvalidIssuer
+	}
+
+	if p.Audience != "" && !contains(t.p.Audience, p.Audience) {
+		return ErrInvalidAudience
+	}
+
+	if p.Subject != nil && !matchesAll(p.Subject, t.p.Subject) {
+		return ErrInvalidSubject
+	}
+
+	if p.MaxAge != 0 {
+		if t.p.IssuedAt == 0 {
+			return ErrMissingIssuedAt
+		}
+		now, issuedAt := t.now().UTC(), t.p.IssuedAt.Time()
+		if now.Sub(issuedAt) > p.MaxAge+t.leeway {
+			return &TimeError{Err: ErrTooOld, Claim: "iat", Time: issuedAt, Now: now}
+		}
+	}
+
+	return nil
+}
+
+// matchesAll is a helper function that returns true if the pattern matches the whole string.
+func matchesAll(re *regexp.Regexp, s string) bool {
+	// the leftmost-longest match covers the whole string if any match does
+	longest := *re
+	longest.Longest()
+	loc := longest.FindStringIndex(s)
+	return loc != nil && loc[0] == 0 && loc[1] == len(s)
+}
+
+// contains is a helper function that returns true if the value is in the list.
+func contains(list []string, value string) bool {
+	for _, s := range list {
+		if s == value {
+			return true
+		}
+	}
+	return false
+}
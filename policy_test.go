@@ -0,0 +1,64 @@
+/*******************************************************************************
+jsonwt - JSON Web Tokens
+Copyright (c) 2022 Michael D Henderson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+******************************************************************************/
+
+package jsonwt_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/mdhender/jsonwt"
+)
+
+func TestPolicySubjectMatchesWholeSubject(t *testing.T) {
+	for _, tc := range []struct {
+		pattern string
+		sub     string
+		ok      bool
+	}{
+		{"admin", "admin", true},
+		{"admin", "notadmin", false},
+		{"admin", "admin2", false},
+		{"admin|administrator", "administrator", true},
+		{"user-[0-9]+", "user-42", true},
+		{"user-[0-9]+", "user-42x", false},
+		{"^user-[0-9]+$", "user-42", true},
+	} {
+		f := jsonwt.NewFactory("k", newHS256(t))
+		tok, err := f.Builder().Subject(tc.sub).ExpiresIn(time.Minute).Build()
+		if err != nil {
+			t.Fatal(err)
+		} else if err = f.Sign(tok); err != nil {
+			t.Fatal(err)
+		}
+		policy := &jsonwt.ValidationPolicy{Subject: regexp.MustCompile(tc.pattern)}
+		err = f.Validate(decodeWithSignature(t, tok, tok.Signature()), policy)
+		if tc.ok && err != nil {
+			t.Errorf("%q: %q: got %v, want nil", tc.pattern, tc.sub, err)
+		} else if !tc.ok && !errors.Is(err, jsonwt.ErrInvalidSubject) {
+			t.Errorf("%q: %q: got %v, want %v", tc.pattern, tc.sub, err, jsonwt.ErrInvalidSubject)
+		}
+	}
+}
@@ -32,3 +32,13 @@ type Signer interface {
 	// Sign returns a slice containing the signature of the message.
 	Sign(msg []byte) ([]byte, error)
 }
+
+// Verifier interface
+type Verifier interface {
+	// Algorithm returns the name of the algorithm used by the verifier.
+	// Example: "RS256"
+	Algorithm() string
+	// Verify returns nil only if sig is a valid signature of the message.
+	// The signature is the raw bytes, not the base64 representation from the Token.
+	Verify(msg, sig []byte) error
+}
@@ -0,0 +1,177 @@
+/*
+ * jsonwt - JSON Web Tokens
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package signers
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+)
+
+// ECDSA implements the jsonwt.Signer and jsonwt.Verifier interfaces using ECDSA.
+type ECDSA struct {
+	alg  string
+	hash crypto.Hash
+	key  *ecdsa.PrivateKey // nil if the signer can only verify signatures
+	pub  *ecdsa.PublicKey
+}
+
+// NewES256 returns a new ES256 signer using ECDSA with P-256 and SHA-256.
+// The private key must be on the P-256 curve.
+// The private key is used to sign messages and its public key is used to verify them.
+func NewES256(key *ecdsa.PrivateKey) (*ECDSA, error) {
+	return newECDSA("ES256", crypto.SHA256, elliptic.P256(), key)
+}
+
+// NewES256Verifier returns a new ES256 signer that can only verify signatures.
+// The public key must be on the P-256 curve.
+func NewES256Verifier(key *ecdsa.PublicKey) (*ECDSA, error) {
+	return newECDSAVerifier("ES256", crypto.SHA256, elliptic.P256(), key)
+}
+
+// NewES384 returns a new ES384 signer using ECDSA with P-384 and SHA-384.
+// The private key must be on the P-384 curve.
+// The private key is used to sign messages and its public key is used to verify them.
+func NewES384(key *ecdsa.PrivateKey) (*ECDSA, error) {
+	return newECDSA("ES384", crypto.SHA384, elliptic.P384(), key)
+}
+
+// NewES384Verifier returns a new ES384 signer that can only verify signatures.
+// The public key must be on the P-384 curve.
+func NewES384Verifier(key *ecdsa.PublicKey) (*ECDSA, error) {
+	return newECDSAVerifier("ES384", crypto.SHA384, elliptic.P384(), key)
+}
+
+// NewES512 returns a new ES512 signer using ECDSA with P-521 and SHA-512.
+// The private key must be on the P-521 curve.
+// The private key is used to sign messages and its public key is used to verify them.
+func NewES512(key *ecdsa.PrivateKey) (*ECDSA, error) {
+	return newECDSA("ES512", crypto.SHA512, elliptic.P521(), key)
+}
+
+// NewES512Verifier returns a new ES512 signer that can only verify signatures.
+// The public key must be on the P-521 curve.
+func NewES512Verifier(key *ecdsa.PublicKey) (*ECDSA, error) {
+	return newECDSAVerifier("ES512", crypto.SHA512, elliptic.P521(), key)
+}
+
+// newECDSA is a helper function that returns a signer for the private key.
+func newECDSA(alg string, hash crypto.Hash, curve elliptic.Curve, key *ecdsa.PrivateKey) (*ECDSA, error) {
+	if key == nil {
+		return nil, ErrMissingKey
+	}
+	s, err := newECDSAVerifier(alg, hash, curve, &key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	s.key = key
+	return s, nil
+}
+
+// newECDSAVerifier is a helper function that returns a signer that can only verify signatures.
+// It returns an error if the key is not on the curve required by the algorithm.
+func newECDSAVerifier(alg string, hash crypto.Hash, curve elliptic.Curve, key *ecdsa.PublicKey) (*ECDSA, error) {
+	if key == nil {
+		return nil, ErrMissingKey
+	} else if key.Curve != curve {
+		return nil, ErrInvalidKey
+	}
+	return &ECDSA{alg: alg, hash: hash, pub: key}, nil
+}
+
+// Algorithm implements the jsonwt.Signer and jsonwt.Verifier interfaces.
+// It returns the "name" of the algorithm used for signing messages.
+func (s *ECDSA) Algorithm() string {
+	return s.alg
+}
+
+// Public returns the public key used to verify signatures.
+// The concrete type is *ecdsa.PublicKey.
+func (s *ECDSA) Public() crypto.PublicKey {
+	return s.pub
+}
+
+// Sign implements the jsonwt.Signer interface.
+// It returns a slice of bytes containing the signature for the message.
+// It returns an error if the signer was created without a private key.
+func (s *ECDSA) Sign(msg []byte) ([]byte, error) {
+	return signECDSA(s.key, s.hash, msg)
+}
+
+// Verify implements the jsonwt.Verifier interface.
+// It returns an error if the signature is not valid for the message.
+func (s *ECDSA) Verify(msg, sig []byte) error {
+	return verifyECDSA(s.pub, s.hash, msg, sig)
+}
+
+// signECDSA is a helper function that signs the message using ECDSA and the given hash.
+// The signature is the concatenation of R and S, each left-padded to the size of the curve,
+// as required by RFC 7518. It is not the ASN.1 DER encoding used by crypto/ecdsa.
+func signECDSA(key *ecdsa.PrivateKey, hash crypto.Hash, msg []byte) ([]byte, error) {
+	if key == nil {
+		return nil, ErrMissingPrivateKey
+	}
+	digest, err := hashMessage(hash, msg)
+	if err != nil {
+		return nil, err
+	}
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest)
+	if err != nil {
+		return nil, err
+	}
+	size := curveSize(key.Curve)
+	sig := make([]byte, 2*size)
+	r.FillBytes(sig[:size])
+	s.FillBytes(sig[size:])
+	return sig, nil
+}
+
+// verifyECDSA is a helper function that verifies an ECDSA signature using the given hash.
+// The signature must be the fixed-width R||S encoding created by signECDSA.
+func verifyECDSA(key *ecdsa.PublicKey, hash crypto.Hash, msg, sig []byte) error {
+	if key == nil {
+		return ErrMissingKey
+	}
+	size := curveSize(key.Curve)
+	if len(sig) != 2*size {
+		return ErrInvalidSignature
+	}
+	digest, err := hashMessage(hash, msg)
+	if err != nil {
+		return err
+	}
+	r := new(big.Int).SetBytes(sig[:size])
+	s := new(big.Int).SetBytes(sig[size:])
+	if !ecdsa.Verify(key, digest, r, s) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// curveSize is a helper function that returns the number of bytes needed to hold a coordinate on the curve.
+func curveSize(curve elliptic.Curve) int {
+	return (curve.Params().BitSize + 7) / 8
+}
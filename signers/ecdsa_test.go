@@ -0,0 +1,168 @@
+/*
+ * jsonwt - JSON Web Tokens
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package signers_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/mdhender/jsonwt"
+	"github.com/mdhender/jsonwt/signers"
+)
+
+// ecVector is a known-good ECDSA signature from testdata along with the public key.
+type ecVector struct {
+	vector
+	Curve string `json:"crv"`
+	X     string `json:"x"`
+	Y     string `json:"y"`
+}
+
+// esAlgorithms maps the ECDSA algorithms to their curves and constructors.
+var esAlgorithms = map[string]struct {
+	curve    elliptic.Curve
+	size     int // bytes in each of R and S
+	signer   func(*ecdsa.PrivateKey) (*signers.ECDSA, error)
+	verifier func(*ecdsa.PublicKey) (*signers.ECDSA, error)
+}{
+	"ES256": {elliptic.P256(), 32, signers.NewES256, signers.NewES256Verifier},
+	"ES384": {elliptic.P384(), 48, signers.NewES384, signers.NewES384Verifier},
+	"ES512": {elliptic.P521(), 66, signers.NewES512, signers.NewES512Verifier},
+}
+
+func TestESVectors(t *testing.T) {
+	var vectors []ecVector
+	loadJSON(t, "../testdata/ecdsa.json", &vectors)
+	if len(vectors) != len(esAlgorithms) {
+		t.Fatalf("vectors: got %d, want %d", len(vectors), len(esAlgorithms))
+	}
+	for _, tc := range vectors {
+		t.Run(tc.Algorithm, func(t *testing.T) {
+			es := esAlgorithms[tc.Algorithm]
+			pub := &ecdsa.PublicKey{Curve: es.curve, X: decodeInt(t, tc.X), Y: decodeInt(t, tc.Y)}
+			v, err := es.verifier(pub)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sig, err := base64.RawURLEncoding.DecodeString(tc.Signature)
+			if err != nil {
+				t.Fatal(err)
+			} else if len(sig) != 2*es.size {
+				t.Fatalf("%s: signature is %d bytes, want %d", tc.Source, len(sig), 2*es.size)
+			}
+			if err = v.Verify([]byte(tc.Input), sig); err != nil {
+				t.Errorf("%s: verify: %v", tc.Source, err)
+			}
+
+			tampered := append([]byte(nil), sig...)
+			tampered[len(tampered)-1] ^= 1
+			for name, bad := range map[string][]byte{
+				"tampered":  tampered,
+				"truncated": sig[:len(sig)-1],
+				"extended":  append(append([]byte(nil), sig...), 0),
+				"r only":    sig[:es.size],
+			} {
+				if err = v.Verify([]byte(tc.Input), bad); !errors.Is(err, signers.ErrInvalidSignature) {
+					t.Errorf("%s: got %v, want %v", name, err, signers.ErrInvalidSignature)
+				}
+			}
+		})
+	}
+}
+
+func TestESFactory(t *testing.T) {
+	for alg, es := range esAlgorithms {
+		t.Run(alg, func(t *testing.T) {
+			key, err := ecdsa.GenerateKey(es.curve, rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s, err := es.signer(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f := jsonwt.NewFactory("k", s)
+			tok, err := f.Token(time.Minute, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sig, err := base64.RawURLEncoding.DecodeString(tok.Signature()); err != nil {
+				t.Fatal(err)
+			} else if len(sig) != 2*es.size {
+				t.Errorf("signature: got %d bytes, want %d", len(sig), 2*es.size)
+			}
+			decoded, err := jsonwt.Decode(tok.String())
+			if err != nil {
+				t.Fatal(err)
+			} else if err = f.Validate(decoded); err != nil {
+				t.Errorf("validate: %v", err)
+			}
+
+			v, err := es.verifier(&key.PublicKey)
+			if err != nil {
+				t.Fatal(err)
+			} else if err = jsonwt.NewVerifierFactory("k", v).Validate(decoded); err != nil {
+				t.Errorf("verifier factory: validate: %v", err)
+			} else if _, err = v.Sign([]byte("msg")); !errors.Is(err, signers.ErrMissingPrivateKey) {
+				t.Errorf("verifier sign: got %v, want %v", err, signers.ErrMissingPrivateKey)
+			}
+		})
+	}
+}
+
+func TestESWrongCurve(t *testing.T) {
+	for alg, es := range esAlgorithms {
+		for other, oes := range esAlgorithms {
+			if other == alg {
+				continue
+			}
+			key, err := ecdsa.GenerateKey(oes.curve, rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err = es.signer(key); !errors.Is(err, signers.ErrInvalidKey) {
+				t.Errorf("%s: %s key: got %v, want %v", alg, other, err, signers.ErrInvalidKey)
+			}
+			if _, err = es.verifier(&key.PublicKey); !errors.Is(err, signers.ErrInvalidKey) {
+				t.Errorf("%s: %s public key: got %v, want %v", alg, other, err, signers.ErrInvalidKey)
+			}
+		}
+	}
+}
+
+// decodeInt is a helper that returns the integer from its base64 representation.
+func decodeInt(t *testing.T, raw string) *big.Int {
+	t.Helper()
+	b, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return new(big.Int).SetBytes(b)
+}
@@ -0,0 +1,94 @@
+/*
+ * jsonwt - JSON Web Tokens
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package signers
+
+import (
+	"crypto"
+	"crypto/ed25519"
+)
+
+// EdDSA implements the jsonwt.Signer and jsonwt.Verifier interfaces using Ed25519.
+type EdDSA struct {
+	key ed25519.PrivateKey // nil if the signer can only verify signatures
+	pub ed25519.PublicKey
+}
+
+// NewEdDSA returns a new EdDSA signer.
+// The private key is used to sign messages and its public key is used to verify them.
+func NewEdDSA(key ed25519.PrivateKey) (*EdDSA, error) {
+	if len(key) == 0 {
+		return nil, ErrMissingKey
+	} else if len(key) != ed25519.PrivateKeySize {
+		return nil, ErrInvalidKey
+	}
+	s := EdDSA{key: make(ed25519.PrivateKey, len(key))}
+	copy(s.key, key)
+	s.pub = s.key.Public().(ed25519.PublicKey)
+	return &s, nil
+}
+
+// NewEdDSAVerifier returns a new EdDSA signer that can only verify signatures.
+func NewEdDSAVerifier(key ed25519.PublicKey) (*EdDSA, error) {
+	if len(key) == 0 {
+		return nil, ErrMissingKey
+	} else if len(key) != ed25519.PublicKeySize {
+		return nil, ErrInvalidKey
+	}
+	s := EdDSA{pub: make(ed25519.PublicKey, len(key))}
+	copy(s.pub, key)
+	return &s, nil
+}
+
+// Algorithm implements the jsonwt.Signer and jsonwt.Verifier interfaces.
+// It returns the "name" of the algorithm used for signing messages.
+func (s *EdDSA) Algorithm() string {
+	return "EdDSA"
+}
+
+// Public returns the public key used to verify signatures.
+// The concrete type is ed25519.PublicKey.
+func (s *EdDSA) Public() crypto.PublicKey {
+	return s.pub
+}
+
+// Sign implements the jsonwt.Signer interface.
+// It returns a slice of bytes containing the signature for the message.
+// It returns an error if the signer was created without a private key.
+func (s *EdDSA) Sign(msg []byte) ([]byte, error) {
+	if s.key == nil {
+		return nil, ErrMissingPrivateKey
+	}
+	return ed25519.Sign(s.key, msg), nil
+}
+
+// Verify implements the jsonwt.Verifier interface.
+// It returns an error if the signature is not valid for the message.
+func (s *EdDSA) Verify(msg, sig []byte) error {
+	if s.pub == nil {
+		return ErrMissingKey
+	} else if !ed25519.Verify(s.pub, msg, sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
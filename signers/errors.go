@@ -0,0 +1,46 @@
+/*
+ * jsonwt - JSON Web Tokens
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package signers
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidSignature = errors.New("invalid signature")
+var ErrMissingKey = errors.New("missing key")
+var ErrMissingPrivateKey = errors.New("missing private key")
+var ErrInvalidKey = errors.New("invalid key")
+
+// KeySizeError is returned when a key is too short to be used with an algorithm.
+type KeySizeError struct {
+	Algorithm string
+	Size      int // length of the key in bits
+	MinSize   int // minimum length of the key in bits
+}
+
+// Error implements the error interface.
+func (e *KeySizeError) Error() string {
+	return fmt.Sprintf("%s: key is %d bits, want at least %d", e.Algorithm, e.Size, e.MinSize)
+}
@@ -0,0 +1,63 @@
+/*
+ * jsonwt - JSON Web Tokens
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package signers
+
+import (
+	"crypto"
+	"crypto/hmac"
+	_ "crypto/sha256" // register SHA-256 for crypto.Hash
+	_ "crypto/sha512" // register SHA-384 and SHA-512 for crypto.Hash
+)
+
+// newHMACKey is a helper function that returns a copy of the secret.
+// It returns an error if the secret is shorter than the output of the hash,
+// as required by RFC 7518 section 3.2.
+func newHMACKey(alg string, hash crypto.Hash, secret []byte) ([]byte, error) {
+	if len(secret) < hash.Size() {
+		return nil, &KeySizeError{Algorithm: alg, Size: 8 * len(secret), MinSize: 8 * hash.Size()}
+	}
+	key := make([]byte, len(secret))
+	copy(key, secret)
+	return key, nil
+}
+
+// signHMAC is a helper function that returns the HMAC of the message using the given hash.
+func signHMAC(key []byte, hash crypto.Hash, msg []byte) ([]byte, error) {
+	hm := hmac.New(hash.New, key)
+	if _, err := hm.Write(msg); err != nil {
+		return nil, err
+	}
+	return hm.Sum(nil), nil
+}
+
+// verifyHMAC is a helper function that compares the signature to the HMAC of the message in constant time.
+func verifyHMAC(key []byte, hash crypto.Hash, msg, sig []byte) error {
+	expected, err := signHMAC(key, hash, msg)
+	if err != nil {
+		return err
+	} else if !hmac.Equal(sig, expected) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+/*
+ * jsonwt - JSON Web Tokens
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package signers_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mdhender/jsonwt"
+	"github.com/mdhender/jsonwt/signers"
+)
+
+// hmacSigner is the interface shared by the HMAC signers.
+type hmacSigner interface {
+	jsonwt.Signer
+	jsonwt.Verifier
+	Secret() []byte
+}
+
+// hsAlgorithms maps the HMAC algorithms to their constructors and minimum secret sizes.
+var hsAlgorithms = map[string]struct {
+	minSize int // bytes
+	signer  func([]byte) (hmacSigner, error)
+}{
+	"HS256": {32, func(secret []byte) (hmacSigner, error) { return signers.NewHS256(secret) }},
+	"HS384": {48, func(secret []byte) (hmacSigner, error) { return signers.NewHS384(secret) }},
+	"HS512": {64, func(secret []byte) (hmacSigner, error) { return signers.NewHS512(secret) }},
+}
+
+func TestHSKeySize(t *testing.T) {
+	for alg, hs := range hsAlgorithms {
+		t.Run(alg, func(t *testing.T) {
+			for _, size := range []int{0, 1, hs.minSize - 1} {
+				var kse *signers.KeySizeError
+				if _, err := hs.signer(make([]byte, size)); !errors.As(err, &kse) {
+					t.Fatalf("%d bytes: got %v, want KeySizeError", size, err)
+				} else if kse.Algorithm != alg || kse.Size != 8*size || kse.MinSize != 8*hs.minSize {
+					t.Errorf("%d bytes: got %+v", size, kse)
+				}
+			}
+			for _, size := range []int{hs.minSize, hs.minSize + 1} {
+				if _, err := hs.signer(bytes.Repeat([]byte{'k'}, size)); err != nil {
+					t.Errorf("%d bytes: got %v, want nil", size, err)
+				}
+			}
+		})
+	}
+}
+
+func TestHSFactory(t *testing.T) {
+	for alg, hs := range hsAlgorithms {
+		t.Run(alg, func(t *testing.T) {
+			secret := bytes.Repeat([]byte{'k'}, hs.minSize)
+			s, err := hs.signer(secret)
+			if err != nil {
+				t.Fatal(err)
+			} else if s.Algorithm() != alg {
+				t.Errorf("algorithm: got %q, want %q", s.Algorithm(), alg)
+			}
+
+			// the signer keeps a copy of the secret
+			secret[0] = 'x'
+			if got := s.Secret(); got[0] != 'k' {
+				t.Errorf("secret: changed by the caller")
+			}
+
+			f := jsonwt.NewFactory("k", s)
+			tok, err := f.Token(time.Minute, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := jsonwt.Decode(tok.String())
+			if err != nil {
+				t.Fatal(err)
+			} else if err = f.Validate(decoded); err != nil {
+				t.Errorf("validate: %v", err)
+			}
+		})
+	}
+}
@@ -21,38 +21,47 @@
  * SOFTWARE.
  */
 
-// Package signers implements jsonwt.Signer types.
+// Package signers implements jsonwt.Signer and jsonwt.Verifier types.
 package signers
 
-import (
-	"crypto/hmac"
-	"crypto/sha256"
-)
+import "crypto"
 
-// HS256 implements the jsonwt.Signer interface using HMAC256.
+// HS256 implements the jsonwt.Signer and jsonwt.Verifier interfaces using HMAC256.
 type HS256 struct {
 	key []byte
 }
 
 // NewHS256 returns a new HMAC256 signer.
+// It returns a KeySizeError if the secret is shorter than 32 bytes.
 func NewHS256(secret []byte) (*HS256, error) {
-	s := HS256{key: make([]byte, len(secret))}
-	copy(s.key, secret)
-	return &s, nil
+	key, err := newHMACKey("HS256", crypto.SHA256, secret)
+	if err != nil {
+		return nil, err
+	}
+	return &HS256{key: key}, nil
 }
 
-// Algorithm implements the jsonwt.Signer interface.
+// Algorithm implements the jsonwt.Signer and jsonwt.Verifier interfaces.
 // It returns the "name" of the algorithm used for signing messages.
 func (s *HS256) Algorithm() string {
 	return "HS256"
 }
 
+// Secret returns a copy of the secret used to sign messages.
+func (s *HS256) Secret() []byte {
+	secret := make([]byte, len(s.key))
+	copy(secret, s.key)
+	return secret
+}
+
 // Sign implements the jsonwt.Signer interface.
 // It returns a slice of bytes containing the signature for the message.
 func (s *HS256) Sign(msg []byte) ([]byte, error) {
-	hm := hmac.New(sha256.New, s.key)
-	if _, err := hm.Write(msg); err != nil {
-		return nil, err
-	}
-	return hm.Sum(nil), nil
+	return signHMAC(s.key, crypto.SHA256, msg)
+}
+
+// Verify implements the jsonwt.Verifier interface.
+// It returns an error if the signature is not valid for the message.
+func (s *HS256) Verify(msg, sig []byte) error {
+	return verifyHMAC(s.key, crypto.SHA256, msg, sig)
 }
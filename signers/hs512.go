@@ -0,0 +1,66 @@
+/*
+ * jsonwt - JSON Web Tokens
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package signers
+
+import "crypto"
+
+// HS512 implements the jsonwt.Signer and jsonwt.Verifier interfaces using HMAC512.
+type HS512 struct {
+	key []byte
+}
+
+// NewHS512 returns a new HMAC512 signer.
+// It returns a KeySizeError if the secret is shorter than 64 bytes.
+func NewHS512(secret []byte) (*HS512, error) {
+	key, err := newHMACKey("HS512", crypto.SHA512, secret)
+	if err != nil {
+		return nil, err
+	}
+	return &HS512{key: key}, nil
+}
+
+// Algorithm implements the jsonwt.Signer and jsonwt.Verifier interfaces.
+// It returns the "name" of the algorithm used for signing messages.
+func (s *HS512) Algorithm() string {
+	return "HS512"
+}
+
+// Secret returns a copy of the secret used to sign messages.
+func (s *HS512) Secret() []byte {
+	secret := make([]byte, len(s.key))
+	copy(secret, s.key)
+	return secret
+}
+
+// Sign implements the jsonwt.Signer interface.
+// It returns a slice of bytes containing the signature for the message.
+func (s *HS512) Sign(msg []byte) ([]byte, error) {
+	return signHMAC(s.key, crypto.SHA512, msg)
+}
+
+// Verify implements the jsonwt.Verifier interface.
+// It returns an error if the signature is not valid for the message.
+func (s *HS512) Verify(msg, sig []byte) error {
+	return verifyHMAC(s.key, crypto.SHA512, msg, sig)
+}
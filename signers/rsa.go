@@ -0,0 +1,242 @@
+/*
+ * jsonwt - JSON Web Tokens
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package signers
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha256" // register SHA-256 for crypto.Hash
+	_ "crypto/sha512" // register SHA-384 and SHA-512 for crypto.Hash
+)
+
+// minRSAKeySize is the minimum size of an RSA modulus in bits, as required by RFC 7518 sections 3.3 and 3.5.
+const minRSAKeySize = 2048
+
+// RSA implements the jsonwt.Signer and jsonwt.Verifier interfaces using RSASSA-PKCS1-v1_5 or RSASSA-PSS.
+type RSA struct {
+	alg  string
+	hash crypto.Hash
+	pss  bool            // true for RSASSA-PSS
+	key  *rsa.PrivateKey // nil if the signer can only verify signatures
+	pub  *rsa.PublicKey
+}
+
+// NewRS256 returns a new RS256 signer using RSASSA-PKCS1-v1_5 with SHA-256.
+// The private key is used to sign messages and its public key is used to verify them.
+// It returns a KeySizeError if the modulus is shorter than 2048 bits.
+func NewRS256(key *rsa.PrivateKey) (*RSA, error) {
+	return newRSA("RS256", crypto.SHA256, false, key)
+}
+
+// NewRS256Verifier returns a new RS256 signer that can only verify signatures.
+// It returns a KeySizeError if the modulus is shorter than 2048 bits.
+func NewRS256Verifier(key *rsa.PublicKey) (*RSA, error) {
+	return newRSAVerifier("RS256", crypto.SHA256, false, key)
+}
+
+// NewRS384 returns a new RS384 signer using RSASSA-PKCS1-v1_5 with SHA-384.
+// The private key is used to sign messages and its public key is used to verify them.
+// It returns a KeySizeError if the modulus is shorter than 2048 bits.
+func NewRS384(key *rsa.PrivateKey) (*RSA, error) {
+	return newRSA("RS384", crypto.SHA384, false, key)
+}
+
+// NewRS384Verifier returns a new RS384 signer that can only verify signatures.
+// It returns a KeySizeError if the modulus is shorter than 2048 bits.
+func NewRS384Verifier(key *rsa.PublicKey) (*RSA, error) {
+	return newRSAVerifier("RS384", crypto.SHA384, false, key)
+}
+
+// NewRS512 returns a new RS512 signer using RSASSA-PKCS1-v1_5 with SHA-512.
+// The private key is used to sign messages and its public key is used to verify them.
+// It returns a KeySizeError if the modulus is shorter than 2048 bits.
+func NewRS512(key *rsa.PrivateKey) (*RSA, error) {
+	return newRSA("RS512", crypto.SHA512, false, key)
+}
+
+// NewRS512Verifier returns a new RS512 signer that can only verify signatures.
+// It returns a KeySizeError if the modulus is shorter than 2048 bits.
+func NewRS512Verifier(key *rsa.PublicKey) (*RSA, error) {
+	return newRSAVerifier("RS512", crypto.SHA512, false, key)
+}
+
+// NewPS256 returns a new PS256 signer using RSASSA-PSS with SHA-256.
+// The private key is used to sign messages and its public key is used to verify them.
+// It returns a KeySizeError if the modulus is shorter than 2048 bits.
+func NewPS256(key *rsa.PrivateKey) (*RSA, error) {
+	return newRSA("PS256", crypto.SHA256, true, key)
+}
+
+// NewPS256Verifier returns a new PS256 signer that can only verify signatures.
+// It returns a KeySizeError if the modulus is shorter than 2048 bits.
+func NewPS256Verifier(key *rsa.PublicKey) (*RSA, error) {
+	return newRSAVerifier("PS256", crypto.SHA256, true, key)
+}
+
+// NewPS384 returns a new PS384 signer using RSASSA-PSS with SHA-384.
+// The private key is used to sign messages and its public key is used to verify them.
+// It returns a KeySizeError if the modulus is shorter than 2048 bits.
+func NewPS384(key *rsa.PrivateKey) (*RSA, error) {
+	return newRSA("PS384", crypto.SHA384, true, key)
+}
+
+// NewPS384Verifier returns a new PS384 signer that can only verify signatures.
+// It returns a KeySizeError if the modulus is shorter than 2048 bits.
+func NewPS384Verifier(key *rsa.PublicKey) (*RSA, error) {
+	return newRSAVerifier("PS384", crypto.SHA384, true, key)
+}
+
+// NewPS512 returns a new PS512 signer using RSASSA-PSS with SHA-512.
+// The private key is used to sign messages and its public key is used to verify them.
+// It returns a KeySizeError if the modulus is shorter than 2048 bits.
+func NewPS512(key *rsa.PrivateKey) (*RSA, error) {
+	return newRSA("PS512", crypto.SHA512, true, key)
+}
+
+// NewPS512Verifier returns a new PS512 signer that can only verify signatures.
+// It returns a KeySizeError if the modulus is shorter than 2048 bits.
+func NewPS512Verifier(key *rsa.PublicKey) (*RSA, error) {
+	return newRSAVerifier("PS512", crypto.SHA512, true, key)
+}
+
+// newRSA is a helper function that returns a signer for the private key.
+func newRSA(alg string, hash crypto.Hash, pss bool, key *rsa.PrivateKey) (*RSA, error) {
+	if key == nil {
+		return nil, ErrMissingKey
+	}
+	s, err := newRSAVerifier(alg, hash, pss, &key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	s.key = key
+	return s, nil
+}
+
+// newRSAVerifier is a helper function that returns a signer that can only verify signatures.
+// It returns a KeySizeError if the modulus is too short, as required by RFC 7518.
+func newRSAVerifier(alg string, hash crypto.Hash, pss bool, key *rsa.PublicKey) (*RSA, error) {
+	if key == nil {
+		return nil, ErrMissingKey
+	} else if key.N == nil {
+		return nil, ErrInvalidKey
+	} else if key.N.BitLen() < minRSAKeySize {
+		return nil, &KeySizeError{Algorithm: alg, Size: key.N.BitLen(), MinSize: minRSAKeySize}
+	}
+	return &RSA{alg: alg, hash: hash, pss: pss, pub: key}, nil
+}
+
+// Algorithm implements the jsonwt.Signer and jsonwt.Verifier interfaces.
+// It returns the "name" of the algorithm used for signing messages.
+func (s *RSA) Algorithm() string {
+	return s.alg
+}
+
+// Public returns the public key used to verify signatures.
+// The concrete type is *rsa.PublicKey.
+func (s *RSA) Public() crypto.PublicKey {
+	return s.pub
+}
+
+// Sign implements the jsonwt.Signer interface.
+// It returns a slice of bytes containing the signature for the message.
+// It returns an error if the signer was created without a private key.
+func (s *RSA) Sign(msg []byte) ([]byte, error) {
+	if s.pss {
+		return signPSS(s.key, s.hash, msg)
+	}
+	return signPKCS1v15(s.key, s.hash, msg)
+}
+
+// Verify implements the jsonwt.Verifier interface.
+// It returns an error if the signature is not valid for the message.
+func (s *RSA) Verify(msg, sig []byte) error {
+	if s.pss {
+		return verifyPSS(s.pub, s.hash, msg, sig)
+	}
+	return verifyPKCS1v15(s.pub, s.hash, msg, sig)
+}
+
+// signPKCS1v15 is a helper function that signs the message using RSASSA-PKCS1-v1_5 and the given hash.
+func signPKCS1v15(key *rsa.PrivateKey, hash crypto.Hash, msg []byte) ([]byte, error) {
+	if key == nil {
+		return nil, ErrMissingPrivateKey
+	}
+	digest, err := hashMessage(hash, msg)
+	if err != nil {
+		return nil, err
+	}
+	return rsa.SignPKCS1v15(rand.Reader, key, hash, digest)
+}
+
+// verifyPKCS1v15 is a helper function that verifies an RSASSA-PKCS1-v1_5 signature using the given hash.
+func verifyPKCS1v15(key *rsa.PublicKey, hash crypto.Hash, msg, sig []byte) error {
+	if key == nil {
+		return ErrMissingKey
+	}
+	digest, err := hashMessage(hash, msg)
+	if err != nil {
+		return err
+	} else if err = rsa.VerifyPKCS1v15(key, hash, digest, sig); err != nil {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// signPSS is a helper function that signs the message using RSASSA-PSS and the given hash.
+// The salt length is the size of the hash, as required by RFC 7518.
+func signPSS(key *rsa.PrivateKey, hash crypto.Hash, msg []byte) ([]byte, error) {
+	if key == nil {
+		return nil, ErrMissingPrivateKey
+	}
+	digest, err := hashMessage(hash, msg)
+	if err != nil {
+		return nil, err
+	}
+	return rsa.SignPSS(rand.Reader, key, hash, digest, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: hash})
+}
+
+// verifyPSS is a helper function that verifies an RSASSA-PSS signature using the given hash.
+// The salt length is detected from the signature so that we can accept tokens from other libraries.
+func verifyPSS(key *rsa.PublicKey, hash crypto.Hash, msg, sig []byte) error {
+	if key == nil {
+		return ErrMissingKey
+	}
+	digest, err := hashMessage(hash, msg)
+	if err != nil {
+		return err
+	} else if err = rsa.VerifyPSS(key, hash, digest, sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto, Hash: hash}); err != nil {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// hashMessage is a helper function that returns the digest of the message.
+func hashMessage(hash crypto.Hash, msg []byte) ([]byte, error) {
+	h := hash.New()
+	if _, err := h.Write(msg); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,192 @@
+/*
+ * jsonwt - JSON Web Tokens
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package signers_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mdhender/jsonwt"
+	"github.com/mdhender/jsonwt/signers"
+)
+
+// vector is a known-good signature from testdata.
+type vector struct {
+	Algorithm string `json:"alg"`
+	Source    string `json:"source"`
+	Input     string `json:"input"`
+	Signature string `json:"signature"`
+}
+
+// psConstructors maps the PSS algorithms to their constructors.
+var psConstructors = map[string]struct {
+	signer   func(*rsa.PrivateKey) (*signers.RSA, error)
+	verifier func(*rsa.PublicKey) (*signers.RSA, error)
+}{
+	"PS256": {signers.NewPS256, signers.NewPS256Verifier},
+	"PS384": {signers.NewPS384, signers.NewPS384Verifier},
+	"PS512": {signers.NewPS512, signers.NewPS512Verifier},
+}
+
+func TestPSVectors(t *testing.T) {
+	key := loadRSAKey(t)
+	var vectors []vector
+	loadJSON(t, "../testdata/rfc7520-ps.json", &vectors)
+	if len(vectors) != len(psConstructors) {
+		t.Fatalf("vectors: got %d, want %d", len(vectors), len(psConstructors))
+	}
+	for _, tc := range vectors {
+		t.Run(tc.Algorithm, func(t *testing.T) {
+			v, err := psConstructors[tc.Algorithm].verifier(&key.PublicKey)
+			if err != nil {
+				t.Fatal(err)
+			} else if v.Algorithm() != tc.Algorithm {
+				t.Errorf("algorithm: got %q, want %q", v.Algorithm(), tc.Algorithm)
+			}
+			sig, err := base64.RawURLEncoding.DecodeString(tc.Signature)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = v.Verify([]byte(tc.Input), sig); err != nil {
+				t.Errorf("%s: verify: %v", tc.Source, err)
+			}
+			sig[len(sig)-1] ^= 1
+			if err = v.Verify([]byte(tc.Input), sig); !errors.Is(err, signers.ErrInvalidSignature) {
+				t.Errorf("%s: tampered: got %v, want %v", tc.Source, err, signers.ErrInvalidSignature)
+			}
+			if _, err = v.Sign([]byte(tc.Input)); !errors.Is(err, signers.ErrMissingPrivateKey) {
+				t.Errorf("verifier sign: got %v, want %v", err, signers.ErrMissingPrivateKey)
+			}
+		})
+	}
+}
+
+func TestPSFactory(t *testing.T) {
+	key := loadRSAKey(t)
+	for alg, c := range psConstructors {
+		t.Run(alg, func(t *testing.T) {
+			s, err := c.signer(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f := jsonwt.NewFactory("bilbo", s)
+			tok, err := f.Token(time.Minute, map[string]string{"name": "bilbo"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := jsonwt.Decode(tok.String())
+			if err != nil {
+				t.Fatal(err)
+			} else if decoded.Algorithm() != alg {
+				t.Errorf("alg: got %q, want %q", decoded.Algorithm(), alg)
+			}
+			if err = f.Validate(decoded); err != nil {
+				t.Errorf("validate: %v", err)
+			}
+
+			// a factory holding only the public key must accept the token
+			v, err := c.verifier(&key.PublicKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = jsonwt.NewVerifierFactory("bilbo", v).Validate(decoded); err != nil {
+				t.Errorf("verifier factory: validate: %v", err)
+			}
+
+			// a token signed by the other PSS algorithms must be rejected
+			for other, oc := range psConstructors {
+				if other == alg {
+					continue
+				}
+				ov, err := oc.verifier(&key.PublicKey)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err = jsonwt.NewVerifierFactory("bilbo", ov).Validate(decoded); !errors.Is(err, jsonwt.ErrNotMyAlgorithm) {
+					t.Errorf("%s: got %v, want %v", other, err, jsonwt.ErrNotMyAlgorithm)
+				}
+			}
+		})
+	}
+}
+
+func TestRSAKeySize(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, newVerifier := range []func(*rsa.PublicKey) (*signers.RSA, error){signers.NewRS256Verifier, signers.NewPS256Verifier} {
+		var kse *signers.KeySizeError
+		if _, err = newVerifier(&key.PublicKey); !errors.As(err, &kse) {
+			t.Fatalf("got %v, want KeySizeError", err)
+		} else if kse.Size != 1024 || kse.MinSize != 2048 {
+			t.Errorf("got %d/%d, want 1024/2048", kse.Size, kse.MinSize)
+		}
+	}
+	if _, err = signers.NewRS256(key); err == nil {
+		t.Errorf("signer: got nil, want KeySizeError")
+	}
+}
+
+// loadRSAKey is a helper that returns the RSA key from RFC 7520 section 3.4.
+func loadRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	var jwk map[string]string
+	loadJSON(t, "../testdata/rfc7520-rsa.json", &jwk)
+	n := func(name string) *big.Int {
+		b, err := base64.RawURLEncoding.DecodeString(jwk[name])
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		return new(big.Int).SetBytes(b)
+	}
+	key := &rsa.PrivateKey{
+		PublicKey: rsa.PublicKey{N: n("n"), E: int(n("e").Int64())},
+		D:         n("d"),
+		Primes:    []*big.Int{n("p"), n("q")},
+	}
+	if err := key.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	key.Precompute()
+	return key
+}
+
+// loadJSON is a helper that unmarshals a file from testdata.
+func loadJSON(t *testing.T, name string, v interface{}) {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	} else if err = json.Unmarshal(data, v); err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+}
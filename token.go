@@ -30,30 +30,48 @@ import (
 
 // IsValid returns true only if the Token is signed, active, and not expired.
 func (t *Token) IsValid() bool {
-	now := time.Now().UTC()
+	return t.Validate() == nil
+}
+
+// Validate returns nil only if the Token is signed, active, and not expired.
+// Otherwise, it returns an error explaining why the Token is not valid:
+// ErrUnsigned, ErrMissingIssuedAt, ErrMissingExpiration, or a *TimeError
+// wrapping ErrExpired or ErrNotYetValid.
+// The timestamps are checked with the clock and leeway of the Factory that validated the signature.
+func (t *Token) Validate() error {
 	if t == nil {
-		//log.Printf("jwt is nil\n")
-		return false
-	} else if !t.isSigned {
-		//log.Printf("alg %q typ %q signed %v borked\n", j.h.Algorithm, j.h.TokenType, j.isSigned)
-		return false
+		return ErrBadToken
+	}
+	now := t.now().UTC()
+	if !t.isSigned {
+		return ErrUnsigned
 	} else if t.p.IssuedAt == 0 {
-		//log.Printf("alg %q typ %q signed %v no issue timestamp\n", j.h.Algorithm, j.h.TokenType, j.isSigned)
-		return false
+		return ErrMissingIssuedAt
 	} else if t.p.ExpirationTime == 0 {
-		//log.Printf("alg %q typ %q signed %v no expiration timestamp\n", j.h.Algorithm, j.h.TokenType, j.isSigned)
-		return false
-	} else if !now.After(time.Unix(t.p.IssuedAt, 0)) {
-		//log.Printf("alg %q typ %q signed %v !now.After(issuedAt) %s %s\n", j.h.Algorithm, j.h.TokenType, j.isSigned, now.Format("2006-01-02T15:04:05.99999999Z"), time.Unix(j.p.IssuedAt, 0).Format("2006-01-02T15:04:05.99999999Z"))
-		return false
-	} else if !time.Unix(t.p.ExpirationTime, 0).After(now) {
-		//log.Printf("alg %q typ %q signed %v !expiresAt.After(now)\n", j.h.Algorithm, j.h.TokenType, j.isSigned)
-		return false
-	} else if t.p.NotBefore != 0 && !now.Before(time.Unix(t.p.NotBefore, 0)) {
-		//log.Printf("alg %q typ %q signed %v !now.Before(notBefore)\n", j.h.Algorithm, j.h.TokenType, j.isSigned)
-		return false
+		return ErrMissingExpiration
+	} else if issuedAt := t.p.IssuedAt.Time(); now.Add(t.leeway).Before(issuedAt) {
+		return &TimeError{Err: ErrNotYetValid, Claim: "iat", Time: issuedAt, Now: now}
+	} else if expiresAt := t.p.ExpirationTime.Time(); !now.Add(-t.leeway).Before(expiresAt) {
+		return &TimeError{Err: ErrExpired, Claim: "exp", Time: expiresAt, Now: now}
+	} else if notBefore := t.p.NotBefore.Time(); t.p.NotBefore != 0 && now.Add(t.leeway).Before(notBefore) {
+		return &TimeError{Err: ErrNotYetValid, Claim: "nbf", Time: notBefore, Now: now}
 	}
-	return true
+	return nil
+}
+
+// Algorithm returns the algorithm from the Token's header ("alg").
+func (t *Token) Algorithm() string {
+	return t.h.Algorithm
+}
+
+// Audience returns a copy of the recipients that the Token is intended for ("aud").
+func (t *Token) Audience() []string {
+	return append([]string(nil), t.p.Audience...)
+}
+
+// ContentType returns the content type from the Token's header ("cty").
+func (t *Token) ContentType() string {
+	return t.h.ContentType
 }
 
 // DeleteCookie removes the cookie associated with the Token.
@@ -61,16 +79,60 @@ func (t *Token) DeleteCookie(w http.ResponseWriter) {
 	DeleteCookie(w)
 }
 
+// ExpiresAt returns the expiration time of the Token ("exp").
+// It returns the zero time if the Token has no expiration time.
+func (t *Token) ExpiresAt() time.Time {
+	return t.p.ExpirationTime.Time()
+}
+
 // Header is a helper function
 func (t *Token) Header() string {
 	return t.h.b64
 }
 
+// ID returns the unique identifier of the Token ("jti").
+func (t *Token) ID() string {
+	return t.p.JWTID
+}
+
+// IssuedAt returns the time at which the Token was issued ("iat").
+// It returns the zero time if the Token has no issue time.
+func (t *Token) IssuedAt() time.Time {
+	return t.p.IssuedAt.Time()
+}
+
+// Issuer returns the principal that issued the Token ("iss").
+func (t *Token) Issuer() string {
+	return t.p.Issuer
+}
+
+// KeyID returns the id of the key used to sign the Token ("kid").
+func (t *Token) KeyID() string {
+	return t.h.KeyID
+}
+
+// NotBefore returns the time on which the Token will start to be accepted ("nbf").
+// It returns the zero time if the Token has no "nbf" claim.
+func (t *Token) NotBefore() time.Time {
+	return t.p.NotBefore.Time()
+}
+
 // Payload is a helper function
 func (t *Token) Payload() string {
 	return t.p.b64
 }
 
+// RawPayload returns the JSON representation of the Token's payload.
+func (t *Token) RawPayload() []byte {
+	if t.p.b64 != "" {
+		if b, err := decode(t.p.b64); err == nil {
+			return b
+		}
+	}
+	b, _ := t.marshalPayload() // the Token has not been decoded or signed
+	return b
+}
+
 // SetCookie associates a cookie with the Token and sends it to the client.
 func (t *Token) SetCookie(w http.ResponseWriter) {
 	SetCookie(w, t)
@@ -86,3 +148,16 @@ func (t *Token) Signature() string {
 func (t *Token) String() string {
 	return t.Header() + "." + t.Payload() + "." + t.Signature()
 }
+
+// Subject returns the subject of the Token ("sub").
+func (t *Token) Subject() string {
+	return t.p.Subject
+}
+
+// now is a helper that returns the current time from the Token's clock.
+func (t *Token) now() time.Time {
+	if t.clock == nil {
+		return time.Now()
+	}
+	return t.clock.Now()
+}
@@ -0,0 +1,101 @@
+/*******************************************************************************
+jsonwt - JSON Web Tokens
+Copyright (c) 2022 Michael D Henderson
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+******************************************************************************/
+
+package jsonwt_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mdhender/jsonwt"
+)
+
+// t0 is the time that tokens are issued in tests.
+var t0 = time.Date(2022, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+func TestTokenValidateTimestamps(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		leeway time.Duration
+		now    time.Duration // offset from t0
+		err    error
+		claim  string
+	}{
+		{"before iat", 0, -time.Second, jsonwt.ErrNotYetValid, "iat"},
+		{"before iat with leeway", 30 * time.Second, -31 * time.Second, jsonwt.ErrNotYetValid, "iat"},
+		{"before nbf", 0, 59 * time.Second, jsonwt.ErrNotYetValid, "nbf"},
+		{"before nbf with leeway", 30 * time.Second, 29 * time.Second, jsonwt.ErrNotYetValid, "nbf"},
+		{"at nbf", 0, time.Minute, nil, ""},
+		{"at nbf with leeway", 30 * time.Second, 30 * time.Second, nil, ""},
+		{"before exp", 0, 10*time.Minute - time.Second, nil, ""},
+		{"at exp", 0, 10 * time.Minute, jsonwt.ErrExpired, "exp"},
+		{"after exp within leeway", 30 * time.Second, 10*time.Minute + 29*time.Second, nil, ""},
+		{"after exp with leeway", 30 * time.Second, 10*time.Minute + 30*time.Second, jsonwt.ErrExpired, "exp"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			clock := jsonwt.NewFakeClock(t0)
+			f := jsonwt.NewFactory("k", newHS256(t))
+			f.SetClock(clock)
+			f.SetLeeway(tc.leeway)
+			tok, err := f.Builder().NotBefore(t0.Add(time.Minute)).ExpiresAt(t0.Add(10 * time.Minute)).Build()
+			if err != nil {
+				t.Fatal(err)
+			} else if err = f.Sign(tok); err != nil {
+				t.Fatal(err)
+			}
+
+			clock.Set(t0.Add(tc.now))
+			decoded := decodeWithSignature(t, tok, tok.Signature())
+			if err = f.Validate(decoded); err != nil {
+				t.Fatal(err)
+			}
+			err = decoded.Validate()
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("got %v, want %v", err, tc.err)
+			} else if tc.err == nil {
+				return
+			}
+			var te *jsonwt.TimeError
+			if !errors.As(err, &te) {
+				t.Fatalf("got %T, want *TimeError", err)
+			} else if te.Claim != tc.claim {
+				t.Errorf("claim: got %q, want %q", te.Claim, tc.claim)
+			} else if !te.Now.Equal(t0.Add(tc.now)) {
+				t.Errorf("now: got %v, want %v", te.Now, t0.Add(tc.now))
+			}
+		})
+	}
+}
+
+func TestTokenValidateUnsigned(t *testing.T) {
+	f := jsonwt.NewFactory("k", newHS256(t))
+	tok, err := f.Token(time.Minute, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a decoded Token is not trusted until the factory validates its signature
+	if err = decodeWithSignature(t, tok, tok.Signature()).Validate(); !errors.Is(err, jsonwt.ErrUnsigned) {
+		t.Errorf("got %v, want %v", err, jsonwt.ErrUnsigned)
+	}
+}
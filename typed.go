@@ -0,0 +1,99 @@
+/*
+ * jsonwt - JSON Web Tokens
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package jsonwt
+
+import (
+	"errors"
+	"time"
+)
+
+// NewTypedFactory returns a factory for tokens whose private claims have the type C.
+// The factory uses f to sign and validate the tokens.
+func NewTypedFactory[C any](f *Factory) *TypedFactory[C] {
+	return &TypedFactory[C]{f: f}
+}
+
+// TypedFactory creates and validates tokens whose private claims have the type C.
+// The claims are stored as top-level members of the payload, as with Builder.Claims.
+type TypedFactory[C any] struct {
+	f *Factory
+}
+
+// Factory returns the factory used to sign and validate tokens.
+func (tf *TypedFactory[C]) Factory() *Factory {
+	return tf.f
+}
+
+// Parse decodes the data and then validates the Token.
+// See Validate for the checks that are made.
+func (tf *TypedFactory[C]) Parse(data string, policies ...*ValidationPolicy) (*TypedToken[C], error) {
+	t, err := Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	return tf.Validate(t, policies...)
+}
+
+// Token is a helper to create a new, signed Token with the given claims.
+func (tf *TypedFactory[C]) Token(ttl time.Duration, claims C) (*TypedToken[C], error) {
+	if tf == nil || tf.f == nil {
+		return nil, ErrBadFactory
+	}
+	t, err := tf.f.Builder().ExpiresIn(ttl).Claims(claims).Build()
+	if err != nil {
+		return nil, err
+	} else if err = tf.f.Sign(t); err != nil {
+		return nil, err
+	}
+	return &TypedToken[C]{Token: t, claims: claims}, nil
+}
+
+// Validate checks the signature (see Factory.Validate) and timestamps (see Token.Validate) of the Token.
+// If the Token is valid, its private claims are decoded once and cached in the returned TypedToken.
+// A Token without private claims (for example, one created from claims that marshal to "{}")
+// has the zero value of C.
+func (tf *TypedFactory[C]) Validate(t *Token, policies ...*ValidationPolicy) (*TypedToken[C], error) {
+	if tf == nil || tf.f == nil {
+		return nil, ErrBadFactory
+	} else if err := tf.f.Validate(t, policies...); err != nil {
+		return nil, err
+	}
+	tt := &TypedToken[C]{Token: t}
+	if err := t.Claim(&tt.claims); err != nil && !errors.Is(err, ErrMissingClaim) {
+		return nil, err
+	}
+	return tt, nil
+}
+
+// TypedToken is a Token with private claims of type C.
+type TypedToken[C any] struct {
+	*Token
+	claims C
+}
+
+// Claims returns the private claims that were decoded when the Token was validated.
+// It hides Token.Claims; use tt.Token.Claims() for every member of the payload.
+func (tt *TypedToken[C]) Claims() C {
+	return tt.claims
+}